@@ -0,0 +1,215 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// 异步写入队列满时的处理策略
+	OverflowBlock          = "block"            // 阻塞等待队列空出位置（默认）
+	OverflowDropNewest     = "drop_newest"      // 丢弃新的条目
+	OverflowDropOldest     = "drop_oldest"      // 丢弃队列中最旧的条目
+	OverflowDropBelowLevel = "drop_below_level" // 丢弃比 AsyncDropLevel 更详细的条目，其余条目阻塞等待
+
+	asyncQueueSizeDef = 1024
+)
+
+// AsyncStats 异步写入统计
+type AsyncStats struct {
+	Written uint64 // 已写入的条目数
+	Dropped uint64 // 因队列满被丢弃的条目数
+	Pending int    // 队列中等待写入的条目数
+}
+
+type asyncRecord struct {
+	data  []byte
+	level logrus.Level
+}
+
+// asyncWriter 有界队列加后台协程的异步写入器
+// 条目由 logrus 在其互斥锁内先格式化再写入，asyncLevelFormatter 在同一把锁内记录当前条目的级别，
+// 因此 Write 可以按级别执行丢弃策略
+type asyncWriter struct {
+	out       io.Writer
+	queue     chan asyncRecord
+	policy    string
+	dropLevel logrus.Level
+
+	level logrus.Level // 当前正在写入条目的级别，由 asyncLevelFormatter 设置
+
+	mu     sync.RWMutex // 保护 closed，写入时持有读锁
+	closed bool
+	done   chan struct{}
+
+	accepted  uint64 // 已进入队列的条目数
+	processed uint64 // 已写入或被丢弃出队列的条目数
+	written   uint64
+	dropped   uint64
+
+	progressMu sync.Mutex
+	progress   chan struct{} // 有 Flush 等待时创建，processed 增加时关闭以通知等待者
+}
+
+func newAsyncWriter(out io.Writer, settings *Settings) *asyncWriter {
+	size := settings.AsyncQueueSize
+	if size <= 0 {
+		size = asyncQueueSizeDef
+	}
+	policy := settings.AsyncOverflow
+	if policy == "" {
+		policy = OverflowBlock
+	}
+
+	w := &asyncWriter{
+		out:       out,
+		queue:     make(chan asyncRecord, size),
+		policy:    policy,
+		dropLevel: settings.AsyncDropLevel,
+		level:     logrus.InfoLevel,
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 实现 io.Writer 接口，将数据复制后放入队列
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	// 关闭后直接同步写入，避免丢失日志
+	if w.closed {
+		return w.out.Write(p)
+	}
+
+	// logrus 会复用缓冲区，必须复制
+	rec := asyncRecord{data: append([]byte(nil), p...), level: w.level}
+
+	select {
+	case w.queue <- rec:
+		atomic.AddUint64(&w.accepted, 1)
+		return len(p), nil
+	default:
+	}
+
+	// 队列已满
+	switch w.policy {
+	case OverflowDropNewest:
+		atomic.AddUint64(&w.dropped, 1)
+		return len(p), nil
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- rec:
+				atomic.AddUint64(&w.accepted, 1)
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-w.queue:
+				atomic.AddUint64(&w.dropped, 1)
+				w.markProcessed()
+			default:
+			}
+		}
+	case OverflowDropBelowLevel:
+		if rec.level > w.dropLevel {
+			atomic.AddUint64(&w.dropped, 1)
+			return len(p), nil
+		}
+	}
+
+	w.queue <- rec
+	atomic.AddUint64(&w.accepted, 1)
+	return len(p), nil
+}
+
+func (w *asyncWriter) run() {
+	defer close(w.done)
+
+	for rec := range w.queue {
+		// 写入错误无法返回给调用方，与同步模式下 logrus 的处理保持一致
+		_, _ = w.out.Write(rec.data)
+		atomic.AddUint64(&w.written, 1)
+		w.markProcessed()
+	}
+}
+
+func (w *asyncWriter) markProcessed() {
+	w.progressMu.Lock()
+	w.processed++
+	if w.progress != nil {
+		close(w.progress)
+		w.progress = nil
+	}
+	w.progressMu.Unlock()
+}
+
+// Flush 等待调用前已进入队列的条目全部写入
+func (w *asyncWriter) Flush(ctx context.Context) error {
+	target := atomic.LoadUint64(&w.accepted)
+
+	for {
+		w.progressMu.Lock()
+		if w.processed >= target {
+			w.progressMu.Unlock()
+			return nil
+		}
+		if w.progress == nil {
+			w.progress = make(chan struct{})
+		}
+		progress := w.progress
+		w.progressMu.Unlock()
+
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close 停止接收新条目并等待队列写完
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+func (w *asyncWriter) stats() AsyncStats {
+	return AsyncStats{
+		Written: atomic.LoadUint64(&w.written),
+		Dropped: atomic.LoadUint64(&w.dropped),
+		Pending: len(w.queue),
+	}
+}
+
+// asyncLevelFormatter 记录当前条目的级别供 asyncWriter 执行丢弃策略
+// logrus 在同一把互斥锁内依次调用 Format 和 Write，因此无需额外同步
+type asyncLevelFormatter struct {
+	logrus.Formatter
+	writer *asyncWriter
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *asyncLevelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	f.writer.level = entry.Level
+	return f.Formatter.Format(entry)
+}
@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gatedWriter 在 gate 关闭前阻塞写入，用于模拟慢速磁盘
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// newBlockedAsyncWriter 创建后台协程阻塞在第一条记录上的异步写入器
+func newBlockedAsyncWriter(t *testing.T, policy string) (*asyncWriter, *gatedWriter) {
+	out := newGatedWriter()
+	settings := NewSettings()
+	settings.AsyncQueueSize = 2
+	settings.AsyncOverflow = policy
+	w := newAsyncWriter(out, settings)
+
+	w.Write([]byte("first\n"))
+	select {
+	case <-out.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("flusher did not start")
+	}
+	return w, out
+}
+
+// TestAsyncWriterOverflowPolicies 测试队列满时的各种策略
+func TestAsyncWriterOverflowPolicies(t *testing.T) {
+	t.Run("DropNewest", func(t *testing.T) {
+		w, out := newBlockedAsyncWriter(t, OverflowDropNewest)
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			w.Write([]byte(msg + "\n"))
+		}
+		close(out.gate)
+		w.Close()
+
+		if got := out.String(); got != "first\na\nb\n" {
+			t.Errorf("output = %q", got)
+		}
+		if stats := w.stats(); stats.Dropped != 2 || stats.Written != 3 {
+			t.Errorf("stats = %+v, want 2 dropped 3 written", stats)
+		}
+	})
+
+	t.Run("DropOldest", func(t *testing.T) {
+		w, out := newBlockedAsyncWriter(t, OverflowDropOldest)
+		for _, msg := range []string{"a", "b", "c", "d"} {
+			w.Write([]byte(msg + "\n"))
+		}
+		close(out.gate)
+		w.Close()
+
+		if got := out.String(); got != "first\nc\nd\n" {
+			t.Errorf("output = %q", got)
+		}
+		if stats := w.stats(); stats.Dropped != 2 {
+			t.Errorf("stats = %+v, want 2 dropped", stats)
+		}
+	})
+
+	t.Run("DropBelowLevel", func(t *testing.T) {
+		w, out := newBlockedAsyncWriter(t, OverflowDropBelowLevel)
+		w.level = logrus.InfoLevel
+		w.Write([]byte("a\n"))
+		w.Write([]byte("b\n"))
+		w.Write([]byte("c\n")) // 队列已满，Info 比 Warn 更详细，被丢弃
+
+		errWritten := make(chan struct{})
+		go func() {
+			w.level = logrus.ErrorLevel
+			w.Write([]byte("error\n")) // 队列已满，Error 阻塞等待
+			close(errWritten)
+		}()
+
+		select {
+		case <-errWritten:
+			t.Fatal("error entry should block while the queue is full")
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(out.gate)
+		<-errWritten
+		w.Close()
+
+		if got := out.String(); got != "first\na\nb\nerror\n" {
+			t.Errorf("output = %q", got)
+		}
+		if stats := w.stats(); stats.Dropped != 1 {
+			t.Errorf("stats = %+v, want 1 dropped", stats)
+		}
+	})
+}
+
+// TestAsyncFlushTimeout 测试 Flush 在上下文超时后返回，且不遗留等待中的协程
+func TestAsyncFlushTimeout(t *testing.T) {
+	w, out := newBlockedAsyncWriter(t, OverflowBlock)
+	defer func() {
+		close(out.gate)
+		w.Close()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Flush() = %v, want deadline exceeded", err)
+	}
+
+	const numFlushes = 20
+	before := runtime.NumGoroutine()
+	for i := 0; i < numFlushes; i++ {
+		if err := w.Flush(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("Flush() = %v, want deadline exceeded", err)
+		}
+	}
+	if leaked := runtime.NumGoroutine() - before; leaked >= numFlushes {
+		t.Errorf("%d goroutines leaked by timed out Flush calls", leaked)
+	}
+}
+
+// TestAsyncLogger 测试异步模式下 Flush 和 Close 写完所有条目
+func TestAsyncLogger(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-async")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "async"
+	settings.MaxSizeMB = 10
+	settings.Async = true
+	settings.AsyncQueueSize = 16
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	countLines := func() int {
+		f, err := os.Open(filepath.Join(root, "async.log"))
+		if err != nil {
+			return 0
+		}
+		defer f.Close()
+		n := 0
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			n++
+		}
+		return n
+	}
+
+	for i := 0; i < 100; i++ {
+		l.Infof("async message %d", i)
+	}
+	if err := l.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(); n != 100 {
+		t.Errorf("after Flush: %d lines, want 100", n)
+	}
+
+	for i := 0; i < 100; i++ {
+		l.Infof("async message %d", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(); n != 200 {
+		t.Errorf("after Close: %d lines, want 200", n)
+	}
+	if stats := l.AsyncStats(); stats.Dropped != 0 {
+		t.Errorf("block policy should not drop entries: %+v", stats)
+	}
+}
+
+// TestValidateAsyncSettings 测试异步配置校验
+func TestValidateAsyncSettings(t *testing.T) {
+	settings := NewSettings()
+	settings.AsyncOverflow = "drop_everything"
+	if err := validateSettings(settings); err == nil || !strings.Contains(err.Error(), "AsyncOverflow") {
+		t.Errorf("expected AsyncOverflow error, got %v", err)
+	}
+}
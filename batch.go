@@ -0,0 +1,327 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	batchSizeDef      = 100
+	batchBytesDef     = 1024 * 1024
+	batchIntervalDef  = time.Second
+	batchQueueSizeDef = 4096
+	batchRetriesDef   = 3
+	batchMinBackoff   = 500 * time.Millisecond
+	batchMaxBackoff   = 5 * time.Second
+	httpTimeoutDef    = 10 * time.Second
+)
+
+// DeliveryStats 批量发送输出的统计
+type DeliveryStats struct {
+	Batches uint64 // 成功发送的批次数
+	Sent    uint64 // 成功发送的条目数
+	Failed  uint64 // 重试后仍发送失败而丢弃的条目数
+	Dropped uint64 // 因队列满被丢弃的条目数
+	Retries uint64 // 重试次数
+	Spooled uint64 // 发送失败后写入磁盘缓冲、等待重发的条目数
+}
+
+// BatchSettings 批量发送的公共配置，零值字段使用默认值
+type BatchSettings struct {
+	BatchSize     int           // 每批最多条目数（默认 100）
+	BatchBytes    int           // 每批最多字节数（默认 1MB）
+	BatchInterval time.Duration // 最长等待时间，到期后发送不足一批的条目（默认 1s）
+	QueueSize     int           // 内存队列长度（默认 4096），队列满时丢弃新的条目
+	MaxRetries    *int          // 失败后的最多重试次数，为 nil 时默认 3，为 0 时不重试
+	MinBackoff    time.Duration // 首次重试等待时间（默认 500ms），之后每次翻倍
+	MaxBackoff    time.Duration // 最长重试等待时间（默认 5s）
+}
+
+func (s BatchSettings) validate() error {
+	if s.BatchSize < 0 || s.BatchBytes < 0 || s.BatchInterval < 0 || s.QueueSize < 0 ||
+		(s.MaxRetries != nil && *s.MaxRetries < 0) || s.MinBackoff < 0 || s.MaxBackoff < 0 {
+		return fmt.Errorf("batch limits cannot be negative")
+	}
+	return nil
+}
+
+// withDefaults 返回填充默认值后的配置
+func (s BatchSettings) withDefaults() BatchSettings {
+	if s.BatchSize == 0 {
+		s.BatchSize = batchSizeDef
+	}
+	if s.BatchBytes == 0 {
+		s.BatchBytes = batchBytesDef
+	}
+	if s.BatchInterval == 0 {
+		s.BatchInterval = batchIntervalDef
+	}
+	if s.QueueSize == 0 {
+		s.QueueSize = batchQueueSizeDef
+	}
+	if s.MaxRetries == nil {
+		retries := batchRetriesDef
+		s.MaxRetries = &retries
+	}
+	if s.MinBackoff == 0 {
+		s.MinBackoff = batchMinBackoff
+	}
+	if s.MaxBackoff == 0 {
+		s.MaxBackoff = batchMaxBackoff
+	}
+	return s
+}
+
+// batchRecord 已序列化的条目，key 用于按流分组（如 Loki 的标签），不需要分组时为空
+type batchRecord struct {
+	key  string
+	data []byte
+}
+
+// errBatchSpooled 由 send 返回，表示整批已写入磁盘缓冲，稍后重发
+var errBatchSpooled = errors.New("batch spooled")
+
+// batcher 在后台协程中按数量、字节数和时间间隔攒批，再调用 send 发送
+// send 返回错误时整批计为失败；重试由 send 自行处理。
+// idle 可选，在没有待发送条目的定时、Flush 和关闭时调用，用于重发磁盘缓冲
+type batcher struct {
+	settings BatchSettings
+	send     func(batch []batchRecord) error
+	idle     func()
+
+	queue   chan batchRecord
+	flushes chan chan struct{}
+	done    chan struct{}
+
+	mu     sync.RWMutex // 保护 closed，入队时持有读锁
+	closed bool
+
+	stats DeliveryStats // 原子访问
+}
+
+func newBatcher(settings BatchSettings, send func(batch []batchRecord) error, idle func()) *batcher {
+	b := &batcher{
+		settings: settings.withDefaults(),
+		send:     send,
+		idle:     idle,
+		flushes:  make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	b.queue = make(chan batchRecord, b.settings.QueueSize)
+	go b.run()
+	return b
+}
+
+// add 将记录放入队列，队列满时丢弃
+func (b *batcher) add(rec batchRecord) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return errors.New("batch sink is closed")
+	}
+	select {
+	case b.queue <- rec:
+	default:
+		atomic.AddUint64(&b.stats.Dropped, 1)
+	}
+	return nil
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.settings.BatchInterval)
+	defer ticker.Stop()
+
+	var (
+		batch []batchRecord
+		size  int
+	)
+	flush := func() {
+		if len(batch) == 0 {
+			if b.idle != nil {
+				b.idle()
+			}
+			return
+		}
+		switch err := b.send(batch); err {
+		case nil:
+			atomic.AddUint64(&b.stats.Batches, 1)
+			atomic.AddUint64(&b.stats.Sent, uint64(len(batch)))
+		case errBatchSpooled:
+			atomic.AddUint64(&b.stats.Spooled, uint64(len(batch)))
+		default:
+			atomic.AddUint64(&b.stats.Failed, uint64(len(batch)))
+		}
+		batch, size = nil, 0
+	}
+	add := func(rec batchRecord) {
+		// 加入后超过字节上限时先发送已有的条目
+		if len(batch) > 0 && size+len(rec.data) > b.settings.BatchBytes {
+			flush()
+		}
+		batch = append(batch, rec)
+		size += len(rec.data)
+		if len(batch) >= b.settings.BatchSize || size >= b.settings.BatchBytes {
+			flush()
+		}
+	}
+
+	for {
+		select {
+		case rec, ok := <-b.queue:
+			if !ok {
+				flush()
+				return
+			}
+			add(rec)
+		case <-ticker.C:
+			flush()
+		case ack := <-b.flushes:
+			// 先取出调用 Flush 前已入队的条目
+			for n := len(b.queue); n > 0; n-- {
+				add(<-b.queue)
+			}
+			flush()
+			close(ack)
+		}
+	}
+}
+
+// Flush 立即发送队列中和正在攒批的条目
+func (b *batcher) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case b.flushes <- ack:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新条目，发送剩余条目后返回
+func (b *batcher) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+
+	<-b.done
+	return nil
+}
+
+func (b *batcher) deliveryStats() DeliveryStats {
+	return DeliveryStats{
+		Batches: atomic.LoadUint64(&b.stats.Batches),
+		Sent:    atomic.LoadUint64(&b.stats.Sent),
+		Failed:  atomic.LoadUint64(&b.stats.Failed),
+		Dropped: atomic.LoadUint64(&b.stats.Dropped),
+		Retries: atomic.LoadUint64(&b.stats.Retries),
+		Spooled: atomic.LoadUint64(&b.stats.Spooled),
+	}
+}
+
+// httpPoster 发送 HTTP POST 请求，网络错误、429 和 5xx 时按指数退避重试，其他 4xx 不重试
+type httpPoster struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+	gzip    bool
+	retries int
+	backoff backoff
+	stats   *DeliveryStats // 记录重试次数，指向所属 batcher 的统计
+}
+
+func newHTTPPoster(url string, headers map[string]string, gzip bool, timeout time.Duration, settings BatchSettings, stats *DeliveryStats) *httpPoster {
+	if timeout == 0 {
+		timeout = httpTimeoutDef
+	}
+	settings = settings.withDefaults()
+	return &httpPoster{
+		client:  &http.Client{Timeout: timeout},
+		url:     url,
+		headers: headers,
+		gzip:    gzip,
+		retries: *settings.MaxRetries,
+		backoff: backoff{min: settings.MinBackoff, max: settings.MaxBackoff},
+		stats:   stats,
+	}
+}
+
+// post 发送请求体，返回最后一次失败的原因
+func (p *httpPoster) post(body []byte, contentType string) error {
+	if p.gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	p.backoff.reset()
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = p.do(body, contentType); err == nil || !retry || attempt >= p.retries {
+			return err
+		}
+		atomic.AddUint64(&p.stats.Retries, 1)
+		time.Sleep(p.backoff.duration())
+	}
+}
+
+// do 发送一次请求，返回失败时是否应重试
+func (p *httpPoster) do(body []byte, contentType string) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if p.gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range p.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	// 读完响应体以便复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("server returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("server returned %s", resp.Status)
+	}
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maximumCallerDepth = 32
+
+var (
+	ownPackage      string // 本包的完整包路径
+	logrusPackage   string // logrus 的完整包路径
+	packageInitOnce sync.Once
+)
+
+func initPackageNames() {
+	packageInitOnce.Do(func() {
+		ownPackage = getPackageName(runtime.FuncForPC(reflect.ValueOf(initPackageNames).Pointer()).Name())
+		logrusPackage = getPackageName(runtime.FuncForPC(reflect.ValueOf(logrus.New).Pointer()).Name())
+	})
+}
+
+// callerHook 修正 logrus 记录的调用者信息
+// 通过包级函数或 *Logger 记录日志时，logrus 会把本包的包装函数当作调用者，这里跳过本包的帧找到真正的调用方
+type callerHook struct{}
+
+// Levels 实现 logrus.Hook 接口
+func (callerHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 实现 logrus.Hook 接口
+func (callerHook) Fire(entry *logrus.Entry) error {
+	if entry.Caller == nil || !isInternalFrame(entry.Caller) {
+		return nil
+	}
+	if f := findCaller(); f != nil {
+		entry.Caller = f
+	}
+	return nil
+}
+
+// findCaller 返回调用栈上第一个不属于 logrus 和本包的帧
+func findCaller() *runtime.Frame {
+	pcs := make([]uintptr, maximumCallerDepth)
+	depth := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	for f, again := frames.Next(); again; f, again = frames.Next() {
+		if !isInternalFrame(&f) {
+			return &f
+		}
+	}
+	return nil
+}
+
+// isInternalFrame 判断帧是否属于 logrus 或本包（测试文件除外）
+func isInternalFrame(f *runtime.Frame) bool {
+	initPackageNames()
+
+	pkg := getPackageName(f.Function)
+	if pkg == logrusPackage {
+		return true
+	}
+	return pkg == ownPackage && !strings.HasSuffix(f.File, "_test.go")
+}
+
+// callerPackage 返回调用者所在的包路径
+func callerPackage(f *runtime.Frame) string {
+	return getPackageName(f.Function)
+}
+
+// getPackageName 从完整函数名中截取包路径
+// 例如 github.com/WQGroup/logger.(*Logger).Close -> github.com/WQGroup/logger
+func getPackageName(f string) string {
+	for {
+		lastPeriod := strings.LastIndex(f, ".")
+		lastSlash := strings.LastIndex(f, "/")
+		if lastPeriod > lastSlash {
+			f = f[:lastPeriod]
+		} else {
+			break
+		}
+	}
+	return f
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestCallerSkipsPackageWrappers 测试通过包级函数记录日志时调用者指向真正的调用方
+func TestCallerSkipsPackageWrappers(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	testLogger := logrus.New()
+	buf := &bytes.Buffer{}
+	testLogger.Out = buf
+	testLogger.Formatter = &WithFieldFormatter{TimestampFormat: "2006-01-02 15:04:05.000"}
+	testLogger.ReportCaller = true
+	testLogger.AddHook(callerHook{})
+	loggerMutex.Lock()
+	defaultLogger = &Logger{Logger: testLogger}
+	loggerMutex.Unlock()
+
+	Info("package level message")
+	WithField("key", "value").Info("entry message")
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.Contains(line, "caller_test.go") {
+			t.Errorf("caller should point to caller_test.go, got %q", line)
+		}
+	}
+}
+
+// TestGetPackageName 测试从函数名截取包路径
+func TestGetPackageName(t *testing.T) {
+	testCases := map[string]string{
+		"github.com/WQGroup/logger.(*Logger).Close": "github.com/WQGroup/logger",
+		"github.com/WQGroup/logger.Info":            "github.com/WQGroup/logger",
+		"main.main":                                 "main",
+		"github.com/a/b.c/d.Func.func1":             "github.com/a/b.c/d",
+	}
+	for in, want := range testCases {
+		if got := getPackageName(in); got != want {
+			t.Errorf("getPackageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -60,8 +60,11 @@ func CleanupExpiredLogs(root string, days int) error {
 		return err
 	}
 
-	// 匹配旧格式的日志文件: basename--YYYYMMDDHHMM--.log
-	re := regexp.MustCompile(`^(.+)--(\d{4})(\d{2})(\d{2})(\d{2})(\d{2})--\.log$`)
+	// 匹配旧格式的日志文件: basename--YYYYMMDDHHMM--.log，组合轮转的 basename--YYYYMMDDHHMM--.N.log，
+	// 以及压缩后的 .log.gz / .log.zst
+	re := regexp.MustCompile(`^(.+)--(\d{4})(\d{2})(\d{2})(\d{2})(\d{2})--(?:\.\d+)?\.log(\.gz|\.zst)?$`)
+	// 匹配大小轮转的备份文件: basename-YYYY-MM-DDTHH-MM-SS.000.log，以及压缩后的文件
+	backupRe := regexp.MustCompile(`^(.+)-(\d{4})-(\d{2})-(\d{2})T(\d{2})-(\d{2})-\d{2}\.\d{3}\.log(\.gz|\.zst)?$`)
 
 	for _, file := range files {
 		if file.IsDir() {
@@ -69,7 +72,10 @@ func CleanupExpiredLogs(root string, days int) error {
 		}
 
 		matches := re.FindStringSubmatch(file.Name())
-		if len(matches) == 7 {
+		if matches == nil {
+			matches = backupRe.FindStringSubmatch(file.Name())
+		}
+		if len(matches) == 8 {
 			year, _ := strconv.Atoi(matches[2])
 			month, _ := strconv.Atoi(matches[3])
 			day, _ := strconv.Atoi(matches[4])
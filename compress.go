@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// 轮转后日志文件的压缩方式
+	CompressNone = ""     // 不压缩（默认）
+	CompressGzip = "gzip" // 压缩为 .gz
+	CompressZstd = "zstd" // 压缩为 .zst
+
+	// lumberjack 备份文件名中的时间格式
+	lumberjackBackupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// compressedExt 返回压缩方式对应的文件扩展名
+func compressedExt(method string) string {
+	switch method {
+	case CompressGzip:
+		return ".gz"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// compressor 在后台协程中压缩轮转后的日志文件
+// Close 会等待正在进行的压缩完成
+type compressor struct {
+	method string
+	onDone func() // 每次压缩完成后调用，用于清理过期的压缩文件
+
+	mu     sync.Mutex // 保护 closed
+	closed bool
+	wg     sync.WaitGroup
+	runMu  sync.Mutex // 串行化压缩任务，避免重复压缩同一个文件
+}
+
+func newCompressor(method string, onDone func()) *compressor {
+	return &compressor{method: method, onDone: onDone}
+}
+
+// compressAsync 在后台压缩单个文件
+func (c *compressor) compressAsync(path string) {
+	c.goAsync(func() {
+		if err := compressFile(path, c.method); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to compress log file %s: %v\n", path, err)
+		}
+	})
+}
+
+// compressBackupsAsync 在后台压缩 lumberjack 产生的所有未压缩备份文件
+func (c *compressor) compressBackupsAsync(filename string) {
+	c.goAsync(func() {
+		for _, path := range lumberjackBackups(filename) {
+			if err := compressFile(path, c.method); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to compress log file %s: %v\n", path, err)
+			}
+		}
+	})
+}
+
+func (c *compressor) goAsync(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.runMu.Lock()
+		defer c.runMu.Unlock()
+		fn()
+		if c.onDone != nil {
+			c.onDone()
+		}
+	}()
+}
+
+// Close 实现 io.Closer 接口，等待正在进行的压缩完成
+func (c *compressor) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	c.mu.Unlock()
+
+	c.wg.Wait()
+	return nil
+}
+
+// compressFile 将文件压缩为 path+扩展名，成功后删除原文件
+func compressFile(path, method string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dstPath := path + compressedExt(method)
+	tmpPath := dstPath + ".tmp"
+	dst, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	var w io.WriteCloser
+	switch method {
+	case CompressGzip:
+		w = gzip.NewWriter(dst)
+	case CompressZstd:
+		if w, err = zstd.NewWriter(dst); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown compress method: %s", method)
+	}
+
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, dstPath); err != nil {
+		return err
+	}
+
+	// 保留原文件的修改时间，按时间清理时与未压缩文件一致
+	_ = os.Chtimes(dstPath, fi.ModTime(), fi.ModTime())
+
+	src.Close()
+	return os.Remove(path)
+}
+
+// lumberjackBackups 返回 lumberjack 为 filename 产生的未压缩备份文件
+// 备份文件名格式：<name>-2006-01-02T15-04-05.000.log
+func lumberjackBackups(filename string) []string {
+	dir := filepath.Dir(filename)
+	ext := filepath.Ext(filename)
+	prefix := strings.TrimSuffix(filepath.Base(filename), ext) + "-"
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var backups []string
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := name[len(prefix) : len(name)-len(ext)]
+		if _, err := time.Parse(lumberjackBackupTimeFormat, ts); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(dir, name))
+	}
+	return backups
+}
@@ -0,0 +1,189 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// readCompressed 解压并读取文件内容
+func readCompressed(t *testing.T, path, method string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var r io.Reader
+	switch method {
+	case CompressGzip:
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer gr.Close()
+		r = gr
+	case CompressZstd:
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestCompressFile 测试 gzip 和 zstd 压缩
+func TestCompressFile(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-compress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, method := range []string{CompressGzip, CompressZstd} {
+		path := filepath.Join(root, "app--202601011200--.log")
+		content := strings.Repeat("compress me\n", 100)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := compressFile(path, method); err != nil {
+			t.Fatalf("%s: compressFile failed: %v", method, err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s: original file should be removed", method)
+		}
+		if got := readCompressed(t, path+compressedExt(method), method); got != content {
+			t.Errorf("%s: decompressed content mismatch", method)
+		}
+	}
+}
+
+// TestCompressSizeRotation 测试大小轮转后压缩备份文件
+func TestCompressSizeRotation(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-compress-size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "size"
+	settings.MaxSizeMB = 1
+	settings.Compress = CompressZstd
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.Repeat("x", 1023)
+	for i := 0; i < 1500; i++ {
+		l.Info(line)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed, _ := filepath.Glob(filepath.Join(root, "size-*.log.zst"))
+	if len(compressed) == 0 {
+		t.Error("expected a compressed backup file")
+	}
+	if backups := lumberjackBackups(filepath.Join(root, "size.log")); len(backups) != 0 {
+		t.Errorf("uncompressed backups should not remain: %v", backups)
+	}
+}
+
+// TestCompressTimeRotation 测试时间轮转后压缩上一个文件
+func TestCompressTimeRotation(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-compress-time")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "time"
+	settings.Compress = CompressGzip
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Info("before rotation")
+	previous := l.CurrentFileName()
+	if err := l.rotateLogsWriter.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮转事件在 rotatelogs 的协程中处理
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(previous + ".gz"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s.gz was not created", previous)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got := readCompressed(t, previous+".gz", CompressGzip); !strings.Contains(got, "before rotation") {
+		t.Errorf("compressed file should contain the rotated entry, got %q", got)
+	}
+}
+
+// TestCleanupCompressedLogs 测试清理能识别压缩后的文件名
+func TestCleanupCompressedLogs(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-cleanup-compressed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	recent := time.Now().Format("200601021504")
+	files := map[string]bool{ // 文件名 -> 是否应被清理
+		"app--202001010000--.log.gz":                                        true,
+		"app--202001010000--.log.zst":                                       true,
+		"app-2020-01-01T00-00-00.000.log.gz":                                true,
+		"app-2020-01-01T00-00-00.000.log":                                   true,
+		"app--" + recent + "--.log.gz":                                      false,
+		"app--202001010000--.log.bak":                                       false,
+		"app-" + time.Now().Format(lumberjackBackupTimeFormat) + ".log.zst": false,
+	}
+	for name := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := CleanupExpiredLogs(root, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, removed := range files {
+		_, err := os.Stat(filepath.Join(root, name))
+		if removed && !os.IsNotExist(err) {
+			t.Errorf("%s should be removed", name)
+		}
+		if !removed && err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+	}
+}
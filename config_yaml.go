@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -15,7 +16,10 @@ type YamlConfig struct {
 	Level               string `yaml:"level"`
 	DaysToKeep          int    `yaml:"days_to_keep"`
 	MaxSizeMB           int    `yaml:"max_size_mb"`
+	MaxBackups          int    `yaml:"max_backups"`
+	MaxTotalSizeMB      int    `yaml:"max_total_size_mb"`
 	UseHierarchicalPath bool   `yaml:"use_hierarchical_path"`
+	RotationPolicy      string `yaml:"rotation_policy"` // size_and_time：按时间和大小组合轮转
 
 	// 新增的格式器配置字段
 	FormatterType    string `yaml:"formatter_type"`
@@ -25,26 +29,220 @@ type YamlConfig struct {
 	DisableCaller    bool   `yaml:"disable_caller"`
 	FullTimestamp    bool   `yaml:"full_timestamp"`
 	LogFormat        string `yaml:"log_format"`
+	TemplateFormat   string `yaml:"template_format"`
+
+	// withField 格式器的字段输出
+	FieldOrder         []string `yaml:"field_order"`
+	NestedFieldsAsJSON bool     `yaml:"nested_fields_as_json"`
+	DisableFieldQuote  bool     `yaml:"disable_field_quote"`
+
+	// logfmt 和 json 格式器的键名
+	TimeKey    string `yaml:"time_key"`
+	LevelKey   string `yaml:"level_key"`
+	MessageKey string `yaml:"message_key"`
+
+	GCPProjectID string `yaml:"gcp_project_id"` // gcp 格式器的项目 ID
+
+	// 命名日志器配置，键为 Named 使用的名称
+	Loggers map[string]YamlLoggerConfig `yaml:"loggers"`
+
+	// 按模块覆盖的日志级别，键为 module 字段值或调用者包路径
+	ModuleLevels map[string]string `yaml:"module_levels"`
+
+	// 异步写入配置
+	Async          bool   `yaml:"async"`
+	AsyncQueueSize int    `yaml:"async_queue_size"`
+	AsyncOverflow  string `yaml:"async_overflow"`
+	AsyncDropLevel string `yaml:"async_drop_level"`
+
+	Compress string `yaml:"compress"` // 轮转后压缩方式：gzip, zstd
+
+	// 按级别另外写入单独文件的路由
+	Routes []YamlRouteConfig `yaml:"routes"`
+
+	// 控制台输出配置
+	DisableConsole       bool   `yaml:"disable_console"`
+	ConsoleOutput        string `yaml:"console_output"` // stderr（默认）, stdout
+	ConsoleLevel         string `yaml:"console_level"`  // 为空时与 level 相同
+	ConsoleFormatterType string `yaml:"console_formatter_type"`
+	ConsoleColors        bool   `yaml:"console_colors"`
+
+	// 文件之外的输出，未配置时不启用
+	Syslog   *YamlSyslogConfig   `yaml:"syslog"`
+	Journald *YamlJournaldConfig `yaml:"journald"`
+	Network  *YamlNetworkConfig  `yaml:"network"`
+	HTTP     *YamlHTTPConfig     `yaml:"http"`
+	Loki     *YamlLokiConfig     `yaml:"loki"`
+	OTLP     *YamlOTLPConfig     `yaml:"otlp"`
+	GELF     *YamlGELFConfig     `yaml:"gelf"`
+	Forward  *YamlForwardConfig  `yaml:"forward"`
+}
+
+// YamlSyslogConfig syslog 输出在 YAML 中的配置
+type YamlSyslogConfig struct {
+	Network  string `yaml:"network"`
+	Address  string `yaml:"address"`
+	Facility string `yaml:"facility"`
+	Tag      string `yaml:"tag"`
+	RFC      string `yaml:"rfc"`
+}
+
+// YamlNetworkConfig TCP/UDP 网络输出在 YAML 中的配置
+type YamlNetworkConfig struct {
+	Network               string        `yaml:"network"`
+	Address               string        `yaml:"address"`
+	FormatterType         string        `yaml:"formatter_type"`
+	TLS                   bool          `yaml:"tls"`
+	TLSCAFile             string        `yaml:"tls_ca_file"`
+	TLSInsecureSkipVerify bool          `yaml:"tls_insecure_skip_verify"`
+	SpoolDir              string        `yaml:"spool_dir"`
+	MaxSpoolMB            int           `yaml:"max_spool_mb"`
+	DisableSpool          bool          `yaml:"disable_spool"`
+	QueueSize             int           `yaml:"queue_size"`
+	MinBackoff            time.Duration `yaml:"min_backoff"` // 如 500ms
+	MaxBackoff            time.Duration `yaml:"max_backoff"` // 如 30s
+}
+
+// YamlBatchConfig 批量输出在 YAML 中的公共配置
+type YamlBatchConfig struct {
+	BatchSize     int           `yaml:"batch_size"`
+	BatchBytes    int           `yaml:"batch_bytes"`
+	BatchInterval time.Duration `yaml:"batch_interval"` // 如 1s
+	QueueSize     int           `yaml:"queue_size"`
+	MaxRetries    *int          `yaml:"max_retries"` // 为 0 时不重试
+	MinBackoff    time.Duration `yaml:"min_backoff"`
+	MaxBackoff    time.Duration `yaml:"max_backoff"`
+}
+
+func (c YamlBatchConfig) settings() BatchSettings {
+	return BatchSettings{
+		BatchSize:     c.BatchSize,
+		BatchBytes:    c.BatchBytes,
+		BatchInterval: c.BatchInterval,
+		QueueSize:     c.QueueSize,
+		MaxRetries:    c.MaxRetries,
+		MinBackoff:    c.MinBackoff,
+		MaxBackoff:    c.MaxBackoff,
+	}
+}
+
+// YamlHTTPConfig HTTP 批量输出在 YAML 中的配置
+type YamlHTTPConfig struct {
+	URL             string            `yaml:"url"`
+	Headers         map[string]string `yaml:"headers"`
+	FormatterType   string            `yaml:"formatter_type"`
+	DisableGzip     bool              `yaml:"disable_gzip"`
+	Timeout         time.Duration     `yaml:"timeout"`
+	YamlBatchConfig `yaml:",inline"`
+}
+
+// YamlLokiConfig Loki 输出在 YAML 中的配置
+type YamlLokiConfig struct {
+	URL             string            `yaml:"url"`
+	Labels          map[string]string `yaml:"labels"`
+	LabelKeys       []string          `yaml:"label_keys"`
+	MaxLabelValues  int               `yaml:"max_label_values"`
+	TenantID        string            `yaml:"tenant_id"`
+	Headers         map[string]string `yaml:"headers"`
+	FormatterType   string            `yaml:"formatter_type"`
+	Encoding        string            `yaml:"encoding"`
+	DisableGzip     bool              `yaml:"disable_gzip"`
+	Timeout         time.Duration     `yaml:"timeout"`
+	YamlBatchConfig `yaml:",inline"`
+}
+
+// YamlOTLPConfig OTLP 日志导出在 YAML 中的配置
+type YamlOTLPConfig struct {
+	Endpoint           string            `yaml:"endpoint"`
+	Headers            map[string]string `yaml:"headers"`
+	ServiceName        string            `yaml:"service_name"`
+	ResourceAttributes map[string]string `yaml:"resource_attributes"`
+	DisableGzip        bool              `yaml:"disable_gzip"`
+	Timeout            time.Duration     `yaml:"timeout"`
+	YamlBatchConfig    `yaml:",inline"`
+}
+
+// YamlGELFConfig GELF 输出在 YAML 中的配置
+type YamlGELFConfig struct {
+	Network               string `yaml:"network"`
+	Address               string `yaml:"address"`
+	Host                  string `yaml:"host"`
+	Compression           string `yaml:"compression"`
+	ChunkSize             int    `yaml:"chunk_size"`
+	TLS                   bool   `yaml:"tls"`
+	TLSCAFile             string `yaml:"tls_ca_file"`
+	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify"`
+	SpoolDir              string `yaml:"spool_dir"`
+	MaxSpoolMB            int    `yaml:"max_spool_mb"`
+	DisableSpool          bool   `yaml:"disable_spool"`
+}
+
+// YamlForwardConfig Fluent forward 输出在 YAML 中的配置
+type YamlForwardConfig struct {
+	Network         string        `yaml:"network"`
+	Address         string        `yaml:"address"`
+	Tag             string        `yaml:"tag"`
+	TagPrefix       string        `yaml:"tag_prefix"`
+	Mode            string        `yaml:"mode"`
+	RequireAck      bool          `yaml:"require_ack"`
+	AckTimeout      time.Duration `yaml:"ack_timeout"`
+	SpoolDir        string        `yaml:"spool_dir"`
+	MaxSpoolMB      int           `yaml:"max_spool_mb"`
+	DisableSpool    bool          `yaml:"disable_spool"`
+	YamlBatchConfig `yaml:",inline"`
+}
+
+// YamlJournaldConfig journald 输出在 YAML 中的配置
+type YamlJournaldConfig struct {
+	Socket     string `yaml:"socket"`
+	Identifier string `yaml:"identifier"`
+}
+
+// YamlRouteConfig 按级别路由在 YAML 中的配置，未设置的轮转与保留项沿用主日志文件的设置
+type YamlRouteConfig struct {
+	Name           string        `yaml:"name"`
+	Level          string        `yaml:"level"`  // 写入此级别及更严重的条目
+	Levels         []string      `yaml:"levels"` // 只写入列出的级别
+	RotationTime   time.Duration `yaml:"rotation_time"`
+	DaysToKeep     int           `yaml:"days_to_keep"`
+	MaxSizeMB      int           `yaml:"max_size_mb"`
+	MaxBackups     int           `yaml:"max_backups"`
+	MaxTotalSizeMB int           `yaml:"max_total_size_mb"`
+	RotationPolicy string        `yaml:"rotation_policy"`
+	Compress       string        `yaml:"compress"`
 }
 
-func parseLevel(s string) logrus.Level {
+// YamlLoggerConfig 命名日志器在 YAML 中的覆盖配置
+type YamlLoggerConfig struct {
+	LogNameBase     string `yaml:"log_name_base"`
+	Level           string `yaml:"level"`
+	DaysToKeep      int    `yaml:"days_to_keep"`
+	MaxSizeMB       int    `yaml:"max_size_mb"`
+	FormatterType   string `yaml:"formatter_type"`
+	TimestampFormat string `yaml:"timestamp_format"`
+	LogFormat       string `yaml:"log_format"`
+	TemplateFormat  string `yaml:"template_format"`
+}
+
+// parseLevel 解析级别名称，不区分大小写，无法识别时返回错误，避免拼写错误悄悄改变日志级别
+func parseLevel(s string) (logrus.Level, error) {
 	switch strings.ToLower(strings.TrimSpace(s)) {
 	case "trace":
-		return logrus.TraceLevel
+		return logrus.TraceLevel, nil
 	case "debug":
-		return logrus.DebugLevel
+		return logrus.DebugLevel, nil
 	case "info":
-		return logrus.InfoLevel
+		return logrus.InfoLevel, nil
 	case "warn", "warning":
-		return logrus.WarnLevel
+		return logrus.WarnLevel, nil
 	case "error":
-		return logrus.ErrorLevel
+		return logrus.ErrorLevel, nil
 	case "fatal":
-		return logrus.FatalLevel
+		return logrus.FatalLevel, nil
 	case "panic":
-		return logrus.PanicLevel
+		return logrus.PanicLevel, nil
 	default:
-		return logrus.InfoLevel
+		return logrus.InfoLevel, fmt.Errorf("unknown level %q", s)
 	}
 }
 
@@ -53,6 +251,11 @@ func LoadSettingsFromYAML(path string) (*Settings, error) {
 	if err != nil {
 		return nil, err
 	}
+	return parseSettingsYAML(b)
+}
+
+// parseSettingsYAML 将 YAML 内容解析为日志设置
+func parseSettingsYAML(b []byte) (*Settings, error) {
 	var cfg YamlConfig
 	if err := yaml.Unmarshal(b, &cfg); err != nil {
 		return nil, err
@@ -70,7 +273,11 @@ func LoadSettingsFromYAML(path string) (*Settings, error) {
 		s.LogNameBase = cfg.LogNameBase
 	}
 	if cfg.Level != "" {
-		s.Level = parseLevel(cfg.Level)
+		level, err := parseLevel(cfg.Level)
+		if err != nil {
+			return nil, fmt.Errorf("level: %w", err)
+		}
+		s.Level = level
 	}
 	if cfg.DaysToKeep > 0 {
 		s.MaxAgeDays = cfg.DaysToKeep
@@ -79,7 +286,16 @@ func LoadSettingsFromYAML(path string) (*Settings, error) {
 	if cfg.MaxSizeMB > 0 {
 		s.MaxSizeMB = cfg.MaxSizeMB
 	}
+	if cfg.MaxBackups > 0 {
+		s.MaxBackups = cfg.MaxBackups
+	}
+	if cfg.MaxTotalSizeMB > 0 {
+		s.MaxTotalSizeMB = cfg.MaxTotalSizeMB
+	}
 	s.UseHierarchicalPath = cfg.UseHierarchicalPath
+	if cfg.RotationPolicy != "" {
+		s.RotationPolicy = cfg.RotationPolicy
+	}
 
 	// 设置新的格式器配置字段
 	if cfg.FormatterType != "" {
@@ -95,6 +311,216 @@ func LoadSettingsFromYAML(path string) (*Settings, error) {
 	if cfg.LogFormat != "" {
 		s.LogFormat = cfg.LogFormat
 	}
+	s.TemplateFormat = cfg.TemplateFormat
+	s.FieldOrder = cfg.FieldOrder
+	s.NestedFieldsAsJSON = cfg.NestedFieldsAsJSON
+	s.DisableFieldQuote = cfg.DisableFieldQuote
+	s.TimeKey = cfg.TimeKey
+	s.LevelKey = cfg.LevelKey
+	s.MessageKey = cfg.MessageKey
+	s.GCPProjectID = cfg.GCPProjectID
+
+	s.Async = cfg.Async
+	if cfg.AsyncQueueSize > 0 {
+		s.AsyncQueueSize = cfg.AsyncQueueSize
+	}
+	if cfg.AsyncOverflow != "" {
+		s.AsyncOverflow = cfg.AsyncOverflow
+	}
+	if cfg.AsyncDropLevel != "" {
+		level, err := parseLevel(cfg.AsyncDropLevel)
+		if err != nil {
+			return nil, fmt.Errorf("async_drop_level: %w", err)
+		}
+		s.AsyncDropLevel = level
+	}
+
+	if cfg.Compress != "" && cfg.Compress != "none" {
+		s.Compress = cfg.Compress
+	}
+
+	if cfg.Syslog != nil {
+		s.Syslog = &SyslogSettings{
+			Network:  cfg.Syslog.Network,
+			Address:  cfg.Syslog.Address,
+			Facility: cfg.Syslog.Facility,
+			Tag:      cfg.Syslog.Tag,
+			RFC:      cfg.Syslog.RFC,
+		}
+	}
+
+	if cfg.Journald != nil {
+		s.Journald = &JournaldSettings{
+			Socket:     cfg.Journald.Socket,
+			Identifier: cfg.Journald.Identifier,
+		}
+	}
+
+	if cfg.Network != nil {
+		s.Network = &NetworkSettings{
+			Network:               cfg.Network.Network,
+			Address:               cfg.Network.Address,
+			FormatterType:         cfg.Network.FormatterType,
+			TLS:                   cfg.Network.TLS,
+			TLSCAFile:             cfg.Network.TLSCAFile,
+			TLSInsecureSkipVerify: cfg.Network.TLSInsecureSkipVerify,
+			SpoolDir:              cfg.Network.SpoolDir,
+			MaxSpoolMB:            cfg.Network.MaxSpoolMB,
+			DisableSpool:          cfg.Network.DisableSpool,
+			QueueSize:             cfg.Network.QueueSize,
+			MinBackoff:            cfg.Network.MinBackoff,
+			MaxBackoff:            cfg.Network.MaxBackoff,
+		}
+	}
+
+	if cfg.HTTP != nil {
+		s.HTTP = &HTTPSettings{
+			URL:           cfg.HTTP.URL,
+			Headers:       cfg.HTTP.Headers,
+			FormatterType: cfg.HTTP.FormatterType,
+			DisableGzip:   cfg.HTTP.DisableGzip,
+			Timeout:       cfg.HTTP.Timeout,
+			BatchSettings: cfg.HTTP.settings(),
+		}
+	}
+
+	if cfg.Loki != nil {
+		s.Loki = &LokiSettings{
+			URL:            cfg.Loki.URL,
+			Labels:         cfg.Loki.Labels,
+			LabelKeys:      cfg.Loki.LabelKeys,
+			MaxLabelValues: cfg.Loki.MaxLabelValues,
+			TenantID:       cfg.Loki.TenantID,
+			Headers:        cfg.Loki.Headers,
+			FormatterType:  cfg.Loki.FormatterType,
+			Encoding:       cfg.Loki.Encoding,
+			DisableGzip:    cfg.Loki.DisableGzip,
+			Timeout:        cfg.Loki.Timeout,
+			BatchSettings:  cfg.Loki.settings(),
+		}
+	}
+
+	if cfg.OTLP != nil {
+		s.OTLP = &OTLPSettings{
+			Endpoint:           cfg.OTLP.Endpoint,
+			Headers:            cfg.OTLP.Headers,
+			ServiceName:        cfg.OTLP.ServiceName,
+			ResourceAttributes: cfg.OTLP.ResourceAttributes,
+			DisableGzip:        cfg.OTLP.DisableGzip,
+			Timeout:            cfg.OTLP.Timeout,
+			BatchSettings:      cfg.OTLP.settings(),
+		}
+	}
+
+	if cfg.GELF != nil {
+		s.GELF = &GELFSettings{
+			Network:               cfg.GELF.Network,
+			Address:               cfg.GELF.Address,
+			Host:                  cfg.GELF.Host,
+			Compression:           cfg.GELF.Compression,
+			ChunkSize:             cfg.GELF.ChunkSize,
+			TLS:                   cfg.GELF.TLS,
+			TLSCAFile:             cfg.GELF.TLSCAFile,
+			TLSInsecureSkipVerify: cfg.GELF.TLSInsecureSkipVerify,
+			SpoolDir:              cfg.GELF.SpoolDir,
+			MaxSpoolMB:            cfg.GELF.MaxSpoolMB,
+			DisableSpool:          cfg.GELF.DisableSpool,
+		}
+	}
+
+	if cfg.Forward != nil {
+		s.Forward = &ForwardSettings{
+			Network:       cfg.Forward.Network,
+			Address:       cfg.Forward.Address,
+			Tag:           cfg.Forward.Tag,
+			TagPrefix:     cfg.Forward.TagPrefix,
+			Mode:          cfg.Forward.Mode,
+			RequireAck:    cfg.Forward.RequireAck,
+			AckTimeout:    cfg.Forward.AckTimeout,
+			SpoolDir:      cfg.Forward.SpoolDir,
+			MaxSpoolMB:    cfg.Forward.MaxSpoolMB,
+			DisableSpool:  cfg.Forward.DisableSpool,
+			BatchSettings: cfg.Forward.settings(),
+		}
+	}
+
+	if len(cfg.ModuleLevels) > 0 {
+		s.ModuleLevels = make(map[string]logrus.Level, len(cfg.ModuleLevels))
+		for module, name := range cfg.ModuleLevels {
+			level, err := parseLevel(name)
+			if err != nil {
+				return nil, fmt.Errorf("module_levels %q: %w", module, err)
+			}
+			s.ModuleLevels[module] = level
+		}
+	}
+
+	s.DisableConsole = cfg.DisableConsole
+	if cfg.ConsoleOutput != "" {
+		s.ConsoleOutput = cfg.ConsoleOutput
+	}
+	if cfg.ConsoleLevel != "" {
+		level, err := parseLevel(cfg.ConsoleLevel)
+		if err != nil {
+			return nil, fmt.Errorf("console_level: %w", err)
+		}
+		s.ConsoleLevel = &level
+	}
+	s.ConsoleFormatterType = cfg.ConsoleFormatterType
+	s.ConsoleColors = cfg.ConsoleColors
+
+	for _, rc := range cfg.Routes {
+		r := LevelRoute{
+			Name:           rc.Name,
+			RotationTime:   rc.RotationTime,
+			MaxAgeDays:     rc.DaysToKeep,
+			MaxSizeMB:      rc.MaxSizeMB,
+			MaxBackups:     rc.MaxBackups,
+			MaxTotalSizeMB: rc.MaxTotalSizeMB,
+			RotationPolicy: rc.RotationPolicy,
+		}
+		if rc.Level != "" {
+			level, err := parseLevel(rc.Level)
+			if err != nil {
+				return nil, fmt.Errorf("route %q level: %w", rc.Name, err)
+			}
+			r.Level = level
+		}
+		for _, name := range rc.Levels {
+			level, err := parseLevel(name)
+			if err != nil {
+				return nil, fmt.Errorf("route %q levels: %w", rc.Name, err)
+			}
+			r.Levels = append(r.Levels, level)
+		}
+		if rc.Compress != "none" {
+			r.Compress = rc.Compress
+		}
+		s.Routes = append(s.Routes, r)
+	}
+
+	if len(cfg.Loggers) > 0 {
+		s.Loggers = make(map[string]*LoggerOverride, len(cfg.Loggers))
+		for name, lc := range cfg.Loggers {
+			o := &LoggerOverride{
+				LogNameBase:     lc.LogNameBase,
+				MaxAgeDays:      lc.DaysToKeep,
+				MaxSizeMB:       lc.MaxSizeMB,
+				FormatterType:   lc.FormatterType,
+				TimestampFormat: lc.TimestampFormat,
+				LogFormat:       lc.LogFormat,
+				TemplateFormat:  lc.TemplateFormat,
+			}
+			if lc.Level != "" {
+				level, err := parseLevel(lc.Level)
+				if err != nil {
+					return nil, fmt.Errorf("logger %q level: %w", name, err)
+				}
+				o.Level = &level
+			}
+			s.Loggers[name] = o
+		}
+	}
 
 	return s, nil
 }
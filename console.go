@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// 控制台输出目标
+	ConsoleOutputStderr = "stderr" // 标准错误（默认）
+	ConsoleOutputStdout = "stdout" // 标准输出
+)
+
+// consoleWriter 返回控制台输出的写入器，禁用控制台或以 Windows GUI 模式运行时返回 nil
+// 在Windows下，如果使用-H=windowsgui编译，os.Stderr将无效，所以需要特殊处理
+func consoleWriter(settings *Settings) io.Writer {
+	if settings.DisableConsole || isWindowsGUI() {
+		return nil
+	}
+	if settings.ConsoleOutput == ConsoleOutputStdout {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+// separateConsole 判断控制台是否需要独立于文件的级别或格式器
+func (s *Settings) separateConsole() bool {
+	return s.ConsoleLevel != nil || s.ConsoleFormatterType != "" || s.ConsoleColors
+}
+
+// consoleFormatter 创建控制台使用的格式器，未设置 ConsoleFormatterType 时与文件使用相同的格式器类型
+// ConsoleColors 对 text 和 template 格式器生效
+func consoleFormatter(settings *Settings) logrus.Formatter {
+	formatter := sinkFormatter(settings, settings.ConsoleFormatterType)
+	if !settings.ConsoleColors {
+		return formatter
+	}
+	switch tf := formatter.(type) {
+	case *logrus.TextFormatter:
+		tf.DisableColors = false
+		tf.ForceColors = true
+	case *TemplateFormatter:
+		tf.Colors = true
+	}
+	return formatter
+}
+
+// newConsoleSink 创建独立格式化并写入控制台的输出
+// 设置了 ConsoleLevel 时按该级别接收条目，否则与文件使用相同的级别过滤
+func newConsoleSink(out io.Writer, settings *Settings) *routeSink {
+	w := &routeSink{
+		formatter:  consoleFormatter(settings),
+		out:        out,
+		followBase: settings.ConsoleLevel == nil,
+	}
+	if settings.ConsoleLevel != nil {
+		for level := logrus.PanicLevel; level <= *settings.ConsoleLevel; level++ {
+			w.levels[level] = true
+		}
+	}
+	if settings.Async {
+		w.async = newAsyncWriter(out, settings)
+		w.closers = []io.Closer{w.async}
+	}
+	return w
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// captureConsole 将 os.Stdout 和 os.Stderr 替换为管道，返回 fn 执行期间写入的内容
+// 日志器在 New 时取用控制台写入器，因此需要在 fn 内创建和关闭日志器
+func captureConsole(t *testing.T, fn func()) (stdout, stderr string) {
+	capture := func(f **os.File) func() string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		orig := *f
+		*f = w
+		done := make(chan string)
+		go func() {
+			var buf bytes.Buffer
+			_, _ = io.Copy(&buf, r)
+			r.Close()
+			done <- buf.String()
+		}()
+		return func() string {
+			*f = orig
+			w.Close()
+			return <-done
+		}
+	}
+
+	restoreOut := capture(&os.Stdout)
+	restoreErr := capture(&os.Stderr)
+	defer func() {
+		stdout = restoreOut()
+		stderr = restoreErr()
+	}()
+	fn()
+	return
+}
+
+// newConsoleTestLogger 在临时目录中创建日志器，返回日志器和文件内容读取函数
+func newConsoleTestLogger(t *testing.T, settings *Settings) (*Logger, func() string) {
+	root, err := os.MkdirTemp("", "logger-ut-console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	settings.MaxSizeMB = 10
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, func() string { return readLogFiles(t, root, "app") }
+}
+
+// TestConsoleOutput 测试输出到标准输出和禁用控制台
+func TestConsoleOutput(t *testing.T) {
+	var file func() string
+	stdout, stderr := captureConsole(t, func() {
+		settings := NewSettings()
+		settings.ConsoleOutput = ConsoleOutputStdout
+		var l *Logger
+		l, file = newConsoleTestLogger(t, settings)
+		l.Info("to stdout")
+		l.Close()
+	})
+	if !strings.Contains(stdout, "to stdout") || strings.Contains(stderr, "to stdout") {
+		t.Errorf("entry should only go to stdout, stdout=%q stderr=%q", stdout, stderr)
+	}
+	if !strings.Contains(file(), "to stdout") {
+		t.Error("entry should be written to file")
+	}
+
+	stdout, stderr = captureConsole(t, func() {
+		settings := NewSettings()
+		settings.DisableConsole = true
+		var l *Logger
+		l, file = newConsoleTestLogger(t, settings)
+		l.Info("file only")
+		l.Close()
+	})
+	if stdout != "" || stderr != "" {
+		t.Errorf("console should be disabled, stdout=%q stderr=%q", stdout, stderr)
+	}
+	if !strings.Contains(file(), "file only") {
+		t.Error("entry should be written to file")
+	}
+}
+
+// TestConsoleLevelAndFormatter 测试控制台使用独立的级别和格式器
+func TestConsoleLevelAndFormatter(t *testing.T) {
+	var file func() string
+	_, stderr := captureConsole(t, func() {
+		settings := NewSettings()
+		settings.Level = logrus.InfoLevel
+		debug := logrus.DebugLevel
+		settings.ConsoleLevel = &debug
+		settings.ConsoleFormatterType = FormatterTypeJSON
+		var l *Logger
+		l, file = newConsoleTestLogger(t, settings)
+		l.Debug("console debug")
+		l.WithField("k", "v").Info("both info")
+		l.Close()
+	})
+
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("console should have 2 lines, got %q", stderr)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &m); err != nil {
+		t.Fatalf("console line should be JSON: %v", err)
+	}
+	if m["msg"] != "both info" || m["k"] != "v" {
+		t.Errorf("unexpected console entry: %v", m)
+	}
+	checkContains(t, "file", file(),
+		[]string{"[INFO]: both info k=v"},
+		[]string{"console debug", `"msg"`})
+
+	_, stderr = captureConsole(t, func() {
+		settings := NewSettings()
+		warn := logrus.WarnLevel
+		settings.ConsoleLevel = &warn
+		var l *Logger
+		l, file = newConsoleTestLogger(t, settings)
+		l.Info("file info")
+		l.Warn("console warn")
+		l.Close()
+	})
+	checkContains(t, "console", stderr, []string{"console warn"}, []string{"file info"})
+	checkContains(t, "file", file(), []string{"file info", "console warn"}, nil)
+}
+
+// TestConsoleColors 测试控制台使用带颜色的 text 格式器，文件不受影响
+func TestConsoleColors(t *testing.T) {
+	var file func() string
+	_, stderr := captureConsole(t, func() {
+		settings := NewSettings()
+		settings.ConsoleFormatterType = FormatterTypeText
+		settings.ConsoleColors = true
+		var l *Logger
+		l, file = newConsoleTestLogger(t, settings)
+		l.Info("colored")
+		l.Close()
+	})
+	if !strings.Contains(stderr, "\x1b[") {
+		t.Errorf("console output should be colored, got %q", stderr)
+	}
+	if out := file(); strings.Contains(out, "\x1b[") || !strings.Contains(out, "colored") {
+		t.Errorf("file output should be plain, got %q", out)
+	}
+}
+
+// TestConsoleValidation 测试控制台配置的校验和 YAML 解析
+func TestConsoleValidation(t *testing.T) {
+	settings := NewSettings()
+	settings.ConsoleOutput = "stdlog"
+	if err := validateSettings(settings); err == nil {
+		t.Error("unknown ConsoleOutput should be invalid")
+	}
+
+	settings = NewSettings()
+	invalid := logrus.Level(9)
+	settings.ConsoleLevel = &invalid
+	if err := validateSettings(settings); err == nil {
+		t.Error("invalid ConsoleLevel should be rejected")
+	}
+
+	s, err := parseSettingsYAML([]byte(`
+formatter_type: json
+console_output: stdout
+console_level: debug
+console_formatter_type: text
+console_colors: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsoleOutput != ConsoleOutputStdout || s.ConsoleLevel == nil || *s.ConsoleLevel != logrus.DebugLevel ||
+		s.ConsoleFormatterType != FormatterTypeText || !s.ConsoleColors || s.DisableConsole {
+		t.Errorf("unexpected console settings: %+v", s)
+	}
+
+	s, err = parseSettingsYAML([]byte("disable_console: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.DisableConsole || s.ConsoleOutput != ConsoleOutputStderr || s.ConsoleLevel != nil {
+		t.Errorf("unexpected console settings: %+v", s)
+	}
+}
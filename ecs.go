@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ecsVersion 输出的 ecs.version
+	ecsVersion = "1.6.0"
+	// ecsTimestampFormat @timestamp 使用 UTC 毫秒精度的 ISO 8601 格式
+	ecsTimestampFormat = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// ecsObject 由 ecsSet 创建的嵌套对象，与字段中本身为 map 的值区分，避免修改调用方的 map
+type ecsObject map[string]interface{}
+
+// ECSFormatter 输出符合 Elastic Common Schema 的 JSON，可以直接写入 Elasticsearch
+// 输出格式：{"@timestamp":"2026-01-02T03:04:05.000Z","log.level":"info","message":"hello","ecs":{"version":"1.6.0"},"service":{"name":"app"},"user":{"id":"42"}}
+//   - 按 ecs-logging 的约定，@timestamp、log.level、message 依次排在最前面
+//   - 调用者写入 log.origin.file.name、log.origin.file.line 和 log.origin.function
+//   - error 字段写入 error.message、error.type，%+v 输出与错误信息不同时（如带堆栈的错误）写入 error.stack_trace
+//   - trace_id/span_id 字段或 ContextWithTrace 中的追踪上下文写入 trace.id、span.id
+//   - 字段名中的 . 表示嵌套，如 http.request.method 输出为 {"http":{"request":{"method":...}}}，
+//     与其他字段冲突时加 fields. 前缀
+type ECSFormatter struct {
+	ServiceName   string // service.name，为空时不输出
+	DisableCaller bool   // 是否禁用调用者信息
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *ECSFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc := ecsObject{}
+	ecsSet(doc, "ecs.version", ecsVersion)
+	if f.ServiceName != "" {
+		ecsSet(doc, "service.name", f.ServiceName)
+	}
+	if !f.DisableCaller && entry.HasCaller() {
+		ecsSet(doc, "log.origin.file.name", entry.Caller.File)
+		ecsSet(doc, "log.origin.file.line", entry.Caller.Line)
+		ecsSet(doc, "log.origin.function", entry.Caller.Function)
+	}
+
+	traceID, spanID := entryTrace(entry, nil)
+	if traceID != "" {
+		ecsSet(doc, "trace.id", traceID)
+	}
+	if spanID != "" {
+		ecsSet(doc, "span.id", spanID)
+	}
+
+	for _, k := range sortedKeys(entry.Data) {
+		v := entry.Data[k]
+		switch k {
+		case logrus.ErrorKey:
+			ecsSetError(doc, v)
+			continue
+		case TraceIDFieldKey, SpanIDFieldKey:
+			// 已写入 trace.id、span.id
+			continue
+		case "@timestamp", "log.level", "message":
+			doc["fields."+k] = v
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = fieldString(err)
+		}
+		if !ecsSet(doc, k, v) {
+			doc["fields."+k] = v
+		}
+	}
+
+	rest, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON: %w", err)
+	}
+
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+	head, err := json.Marshal(struct {
+		Timestamp string `json:"@timestamp"`
+		Level     string `json:"log.level"`
+		Message   string `json:"message"`
+	}{entry.Time.UTC().Format(ecsTimestampFormat), entry.Level.String(), entry.Message})
+	if err != nil {
+		return nil, err
+	}
+	b.Write(head[:len(head)-1])
+	b.WriteByte(',')
+	b.Write(rest[1:])
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// ecsSetError 将 error 字段写入 error.*
+func ecsSetError(doc ecsObject, v interface{}) {
+	err, ok := v.(error)
+	if !ok {
+		ecsSet(doc, "error.message", fieldString(v))
+		return
+	}
+	msg := fieldString(err)
+	ecsSet(doc, "error.message", msg)
+	ecsSet(doc, "error.type", fmt.Sprintf("%T", err))
+	if stack := fmt.Sprintf("%+v", err); stack != msg {
+		ecsSet(doc, "error.stack_trace", stack)
+	}
+}
+
+// ecsSet 按 . 分隔的路径把值写入嵌套的 map，路径上已有非 map 的值或目标已存在时返回 false
+func ecsSet(doc ecsObject, key string, v interface{}) bool {
+	parts := strings.Split(key, ".")
+	for _, p := range parts {
+		if p == "" {
+			// 以 . 开头、结尾或包含连续 . 的键不嵌套
+			if _, ok := doc[key]; ok {
+				return false
+			}
+			doc[key] = v
+			return true
+		}
+	}
+
+	m := doc
+	for _, p := range parts[:len(parts)-1] {
+		child, ok := m[p]
+		if !ok {
+			next := ecsObject{}
+			m[p] = next
+			m = next
+			continue
+		}
+		next, ok := child.(ecsObject)
+		if !ok {
+			return false
+		}
+		m = next
+	}
+
+	leaf := parts[len(parts)-1]
+	if _, ok := m[leaf]; ok {
+		return false
+	}
+	m[leaf] = v
+	return true
+}
@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stackError 在 %+v 时输出堆栈的错误，模拟 github.com/pkg/errors
+type stackError struct{ msg string }
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprintf(s, "%s\nmain.run\n\t/src/main.go:10", e.msg)
+		return
+	}
+	fmt.Fprint(s, e.msg)
+}
+
+// TestECSFormatter 测试 ECS 字段映射、嵌套和冲突处理
+func TestECSFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Date(2026, 1, 2, 11, 4, 5, 6e6, time.FixedZone("CST", 8*3600))
+	entry.Level = logrus.ErrorLevel
+	entry.Message = "request failed"
+	entry.Caller = &runtime.Frame{File: "/src/app/main.go", Line: 42, Function: "main.handle"}
+	entry.Logger.ReportCaller = true
+	userMap := map[string]interface{}{"name": "alice"}
+	entry.Data = logrus.Fields{
+		"error":               &stackError{msg: "boom"},
+		"error.code":          "E1",
+		"http.request.method": "GET",
+		"http.response":       200,
+		"http.response.bytes": 10,
+		"user":                userMap,
+		"user.id":             "42",
+		"message":             "dup",
+		"cause":               errors.New("disk full"),
+		TraceIDFieldKey:       "4bf92f3577b34da6a3ce929d0e0e4736",
+	}
+	entry.Context = ContextWithTrace(context.Background(), "", "00f067aa0ba902b7")
+
+	b, err := (&ECSFormatter{ServiceName: "app"}).Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"@timestamp":"2026-01-02T03:04:05.006Z","log.level":"error","message":"request failed",`; !strings.HasPrefix(string(b), want) {
+		t.Errorf("output should start with %s, got %s", want, b)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) interface{} {
+		var v interface{} = doc
+		for _, p := range strings.Split(path, "/") {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			v = m[p]
+		}
+		return v
+	}
+	want := map[string]interface{}{
+		"ecs/version":                ecsVersion,
+		"service/name":               "app",
+		"log/origin/file/name":       "/src/app/main.go",
+		"log/origin/file/line":       float64(42),
+		"log/origin/function":        "main.handle",
+		"error/message":              "boom",
+		"error/type":                 "*logger.stackError",
+		"error/stack_trace":          "boom\nmain.run\n\t/src/main.go:10",
+		"error/code":                 "E1",
+		"http/request/method":        "GET",
+		"http/response":              float64(200),
+		"fields.http.response.bytes": float64(10),
+		"user/name":                  "alice",
+		"fields.user.id":             "42",
+		"fields.message":             "dup",
+		"cause":                      "disk full",
+		"trace/id":                   "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span/id":                    "00f067aa0ba902b7",
+	}
+	for path, w := range want {
+		if got := get(path); got != w {
+			t.Errorf("%s = %#v, want %#v", path, got, w)
+		}
+	}
+	if _, ok := doc[TraceIDFieldKey]; ok {
+		t.Error("trace_id should not be repeated")
+	}
+	if len(userMap) != 1 {
+		t.Errorf("caller's map should not be modified: %v", userMap)
+	}
+}
+
+// TestECSFormatterType 测试通过 FormatterType 选择 ECS 格式器，service.name 取自 LogNameBase
+func TestECSFormatterType(t *testing.T) {
+	s, err := parseSettingsYAML([]byte("formatter_type: ecs\nlog_name_base: billing\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := (&FormatterFactory{}).CreateFormatter(s).(*ECSFormatter)
+	if !ok {
+		t.Fatal("expected ECSFormatter")
+	}
+	if f.ServiceName != "billing" {
+		t.Errorf("unexpected formatter: %+v", f)
+	}
+
+	out := formatWithFields(t, f, logrus.Fields{"error": "plain"})
+	if !strings.Contains(out, `"error":{"message":"plain"}`) || strings.Contains(out, `"log":`) {
+		t.Errorf("unexpected output %s", out)
+	}
+}
+
+// TestECSFormatterNilError 测试值为 nil 指针的 error 字段不会 panic
+func TestECSFormatterNilError(t *testing.T) {
+	var typedNil *otlpTestError
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "hello"
+	entry.Data = logrus.Fields{logrus.ErrorKey: typedNil, "cause": typedNil}
+	b, err := (&ECSFormatter{DisableCaller: true}).Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Cause string `json:"cause"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Cause != "<nil>" || doc.Error.Message != "<nil>" {
+		t.Errorf("unexpected output %s", b)
+	}
+}
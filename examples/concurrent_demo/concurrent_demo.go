@@ -9,7 +9,7 @@ import (
 )
 
 func main() {
-	fmt.Println("=== 并发安全验证演示 ===\n")
+	fmt.Print("=== 并发安全验证演示 ===\n\n")
 
 	// 设置日志器
 	settings := logger.NewSettings()
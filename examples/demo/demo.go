@@ -30,7 +30,7 @@ func (f *MyFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 }
 
 func main() {
-	fmt.Println("=== Logger 格式器演示 ===\n")
+	fmt.Print("=== Logger 格式器演示 ===\n\n")
 
 	// 1. 使用默认的 withField 格式器
 	fmt.Println("1. 默认 withField 格式器:")
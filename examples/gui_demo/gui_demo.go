@@ -9,7 +9,7 @@ import (
 )
 
 func main() {
-	fmt.Println("=== Windows GUI 模式检测验证 ===\n")
+	fmt.Print("=== Windows GUI 模式检测验证 ===\n\n")
 
 	// 显示当前系统信息
 	fmt.Printf("操作系统: %s\n", runtime.GOOS)
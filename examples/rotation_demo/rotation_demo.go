@@ -11,7 +11,7 @@ import (
 )
 
 func main() {
-	fmt.Println("=== 日志轮转与清理功能演示 ===\n")
+	fmt.Print("=== 日志轮转与清理功能演示 ===\n\n")
 
 	// 演示1: 时间轮转功能
 	demonstrateTimeRotation()
@@ -58,7 +58,7 @@ func demonstrateTimeRotation() {
 	}
 
 	fmt.Printf("当前日志文件: %s\n", logger.CurrentFileName())
-	fmt.Println("注意：每5秒会创建新的日志文件\n")
+	fmt.Print("注意：每5秒会创建新的日志文件\n\n")
 }
 
 // demonstrateSizeRotation 演示大小轮转功能
@@ -87,7 +87,7 @@ func demonstrateSizeRotation() {
 	}
 
 	fmt.Printf("当前日志文件: %s\n", logger.CurrentFileName())
-	fmt.Println("注意：文件超过1MB时会自动轮转\n")
+	fmt.Print("注意：文件超过1MB时会自动轮转\n\n")
 }
 
 // demonstrateHierarchicalPath 演示分层路径结构
@@ -126,7 +126,7 @@ func demonstrateHierarchicalPath() {
 		return nil
 	})
 
-	fmt.Println("注意：日志按 年/月/日 的层次结构存储\n")
+	fmt.Print("注意：日志按 年/月/日 的层次结构存储\n\n")
 }
 
 // demonstrateAutoCleanup 演示自动清理功能
@@ -166,7 +166,7 @@ func demonstrateAutoCleanup() {
 		return nil
 	})
 
-	fmt.Println("注意：超过保存时间的日志会被自动删除\n")
+	fmt.Print("注意：超过保存时间的日志会被自动删除\n\n")
 }
 
 // demonstrateEasyFormatter 演示 Easy 格式器
@@ -195,5 +195,5 @@ func demonstrateEasyFormatter() {
 		"ip":     "127.0.0.1",
 	}).Info("用户登录成功")
 
-	fmt.Println("注意：Easy 格式器支持自定义日志格式模板\n")
+	fmt.Print("注意：Easy 格式器支持自定义日志格式模板\n\n")
 }
\ No newline at end of file
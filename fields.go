@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// orderedKeys 返回字段名，order 中列出且存在的字段按列出的顺序排在前面，其余字段按名称排序
+func orderedKeys(data logrus.Fields, order []string) []string {
+	if len(order) == 0 {
+		return sortedKeys(data)
+	}
+
+	keys := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(order))
+	for _, k := range order {
+		if _, ok := data[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	for _, k := range sortedKeys(data) {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// fieldText 将字段值转换为文本
+// nestedJSON 为 true 时 map、slice、array、struct 类型的值输出为 JSON，JSON 本身可以界定边界，不再加引号；
+// quote 为 true 时对包含空白、引号、= 或控制字符的值加引号并转义，输出可以按空格和 = 可靠地解析回来
+func fieldText(v interface{}, nestedJSON, quote bool) string {
+	if nestedJSON && isNestedValue(v) {
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	s := fieldString(v)
+	if quote && needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// isNestedValue 判断值是否为嵌套结构，实现了 error 或 fmt.Stringer 的值按文本输出
+func isNestedValue(v interface{}) bool {
+	switch v.(type) {
+	case nil, error, fmt.Stringer, []byte:
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// needsQuote 判断值是否需要加引号
+func needsQuote(s string) bool {
+	for _, r := range s {
+		if r == utf8.RuneError || r == '=' || r == '"' || r == '\\' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
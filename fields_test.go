@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func formatWithFields(t *testing.T, f logrus.Formatter, data logrus.Fields) string {
+	t.Helper()
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry.Level = logrus.InfoLevel
+	entry.Message = "hello"
+	entry.Data = data
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// TestWithFieldFormatterFields 测试字段排序、引号转义和嵌套值输出
+func TestWithFieldFormatterFields(t *testing.T) {
+	data := logrus.Fields{
+		"user":    "li lei",
+		"id":      42,
+		"b":       "x=y",
+		"quote":   `say "hi"`,
+		"line":    "a\nb",
+		"empty":   "",
+		"name":    "张三",
+		"error":   errors.New("boom failed"),
+		"request": map[string]interface{}{"path": "/a", "code": 200},
+		"tags":    []string{"x", "y"},
+	}
+
+	f := &WithFieldFormatter{DisableTimestamp: true}
+	want := `[INFO]: hello b="x=y" empty= error="boom failed" id=42 line="a\nb" name=张三 quote="say \"hi\"" ` +
+		`request="map[code:200 path:/a]" tags="[x y]" user="li lei"` + "\n"
+	for i := 0; i < 5; i++ {
+		if got := formatWithFields(t, f, data); got != want {
+			t.Fatalf("got  %q\nwant %q", got, want)
+		}
+	}
+
+	f = &WithFieldFormatter{DisableTimestamp: true, FieldOrder: []string{"user", "missing", "id"}, NestedAsJSON: true}
+	want = `[INFO]: hello user="li lei" id=42 b="x=y" empty= error="boom failed" line="a\nb" name=张三 quote="say \"hi\"" ` +
+		`request={"code":200,"path":"/a"} tags=["x","y"]` + "\n"
+	if got := formatWithFields(t, f, data); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	f = &WithFieldFormatter{DisableTimestamp: true, DisableLevel: true, DisableQuote: true}
+	if got := formatWithFields(t, f, logrus.Fields{"user": "li lei", "at": time.Second}); got != "hello at=1s user=li lei\n" {
+		t.Errorf("unquoted output = %q", got)
+	}
+}
+
+// TestFieldSettingsYAML 测试字段输出配置的 YAML 解析
+func TestFieldSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+field_order: [request_id, user]
+nested_fields_as_json: true
+disable_field_quote: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.FieldOrder) != 2 || s.FieldOrder[0] != "request_id" || !s.NestedFieldsAsJSON || !s.DisableFieldQuote {
+		t.Errorf("unexpected settings: %+v", s)
+	}
+
+	f, ok := (&FormatterFactory{}).CreateFormatter(s).(*WithFieldFormatter)
+	if !ok {
+		t.Fatal("expected WithFieldFormatter")
+	}
+	if len(f.FieldOrder) != 2 || !f.NestedAsJSON || !f.DisableQuote {
+		t.Errorf("formatter options not applied: %+v", f)
+	}
+}
@@ -0,0 +1,340 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Fluent forward 协议的发送模式
+	ForwardModeForward                 = "forward"                   // [tag, [[time, record], ...], option]（默认）
+	ForwardModePackedForward           = "packed_forward"            // [tag, bin(连续的 [time, record]), option]
+	ForwardModeCompressedPackedForward = "compressed_packed_forward" // 与 packed_forward 相同，条目经 gzip 压缩
+
+	forwardAddressDef    = "127.0.0.1:24224"
+	forwardAckTimeoutDef = 5 * time.Second
+)
+
+// ForwardSettings Fluentd / Fluent Bit forward 协议输出配置
+type ForwardSettings struct {
+	Network    string        // "tcp"（默认）或 "unix"
+	Address    string        // 地址（默认 "127.0.0.1:24224"），unix 时为套接字路径
+	Tag        string        // 标签（默认 LogNameBase）
+	TagPrefix  string        // 标签前缀，设置后标签为 <TagPrefix>.<Tag>
+	Mode       string        // 发送模式（默认 "forward"）
+	RequireAck bool          // 是否要求 agent 确认每一批
+	AckTimeout time.Duration // 等待确认的超时时间（默认 5s）
+
+	// 发送失败的批次写入磁盘缓冲，agent 恢复后按顺序重发
+	SpoolDir     string // 缓冲目录（默认 <日志目录>/spool/<LogNameBase>-forward）
+	MaxSpoolMB   int    // 缓冲上限(MB)，超出时丢弃最旧的批次（默认 100）
+	DisableSpool bool   // 禁用磁盘缓冲，发送失败时按 MaxRetries 重试后丢弃
+
+	BatchSettings // 批量与重试配置
+}
+
+// validate 验证 forward 输出配置
+func (s *ForwardSettings) validate() error {
+	switch s.Network {
+	case "", "tcp", "unix":
+	default:
+		return fmt.Errorf("unknown forward network: %s", s.Network)
+	}
+	switch s.Mode {
+	case "", ForwardModeForward, ForwardModePackedForward, ForwardModeCompressedPackedForward:
+	default:
+		return fmt.Errorf("unknown forward mode: %s", s.Mode)
+	}
+	if s.AckTimeout < 0 || s.MaxSpoolMB < 0 {
+		return fmt.Errorf("forward limits cannot be negative")
+	}
+	return s.BatchSettings.validate()
+}
+
+// forwardSink 以 Fluent forward 协议将条目攒批发送到 Fluentd / Fluent Bit
+// 每批条目先编码为“4 字节条目数 + 连续的 [time, record]”，发送时再按模式包装，
+// 因此写入磁盘缓冲的批次与发送模式无关。开启缓冲时发送失败的批次立即写入缓冲，
+// 之后的批次在缓冲重发完之前也写入缓冲，以保持顺序；未开启缓冲时按 MaxRetries 重试。
+// 除 Send 外的方法都只在 batcher 的后台协程中调用
+type forwardSink struct {
+	network    string
+	address    string
+	tag        string
+	mode       string
+	requireAck bool
+	ackTimeout time.Duration
+	retries    int
+	backoff    backoff
+	spool      *diskSpool
+
+	conn    net.Conn
+	reader  *bufio.Reader
+	retryAt time.Time // 断开后下次允许重连的时间
+
+	*batcher
+}
+
+func newForwardSink(s *ForwardSettings, settings *Settings) (*forwardSink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	bs := s.BatchSettings.withDefaults()
+	w := &forwardSink{
+		network:    s.Network,
+		address:    s.Address,
+		tag:        s.Tag,
+		mode:       s.Mode,
+		requireAck: s.RequireAck,
+		ackTimeout: s.AckTimeout,
+		retries:    *bs.MaxRetries,
+		backoff:    backoff{min: bs.MinBackoff, max: bs.MaxBackoff},
+	}
+	if w.network == "" {
+		w.network = "tcp"
+	}
+	if w.address == "" {
+		w.address = forwardAddressDef
+	}
+	if w.tag == "" {
+		w.tag = settings.LogNameBase
+	}
+	if s.TagPrefix != "" {
+		w.tag = s.TagPrefix + "." + w.tag
+	}
+	if w.mode == "" {
+		w.mode = ForwardModeForward
+	}
+	if w.ackTimeout == 0 {
+		w.ackTimeout = forwardAckTimeoutDef
+	}
+
+	if !s.DisableSpool {
+		dir := s.SpoolDir
+		if dir == "" {
+			dir = filepath.Join(settings.logDir(), "spool", settings.LogNameBase+"-forward")
+		}
+		maxMB := s.MaxSpoolMB
+		if maxMB == 0 {
+			maxMB = networkMaxSpoolMBDef
+		}
+		spool, err := openDiskSpool(dir, int64(maxMB)*1024*1024)
+		if err != nil {
+			return nil, err
+		}
+		w.spool = spool
+	}
+
+	w.batcher = newBatcher(s.BatchSettings, w.send, w.replay)
+	return w, nil
+}
+
+// Send 实现 sink 接口，将条目编码为 [EventTime, record]
+// record 与 JSON 格式器的键相同：字段、msg、level，开启调用者信息时还有 file 和 func
+func (w *forwardSink) Send(entry *logrus.Entry) error {
+	reserved := map[string]bool{"msg": true, "level": true}
+	n := len(entry.Data) + 2
+	if entry.HasCaller() {
+		reserved["file"], reserved["func"] = true, true
+		n += 2
+	}
+
+	b := appendMsgpackArrayHeader(nil, 2)
+	b = appendMsgpackEventTime(b, entry.Time)
+	b = appendMsgpackMapHeader(b, n)
+	for _, k := range sortedKeys(entry.Data) {
+		// 与保留键冲突的字段加 fields. 前缀，与 logrus 的处理一致
+		name := k
+		if reserved[k] {
+			name = "fields." + k
+		}
+		b = appendMsgpackString(b, name)
+		b = appendMsgpackValue(b, entry.Data[k])
+	}
+	b = appendMsgpackString(b, "msg")
+	b = appendMsgpackString(b, entry.Message)
+	b = appendMsgpackString(b, "level")
+	b = appendMsgpackString(b, entry.Level.String())
+	if entry.HasCaller() {
+		b = appendMsgpackString(b, "file")
+		b = appendMsgpackString(b, entry.Caller.File+":"+strconv.Itoa(entry.Caller.Line))
+		b = appendMsgpackString(b, "func")
+		b = appendMsgpackString(b, entry.Caller.Function)
+	}
+	return w.add(batchRecord{data: b})
+}
+
+// send 发送一批条目
+func (w *forwardSink) send(batch []batchRecord) error {
+	chunk := make([]byte, 4, 4+len(batch)*128)
+	binary.BigEndian.PutUint32(chunk, uint32(len(batch)))
+	for _, rec := range batch {
+		chunk = append(chunk, rec.data...)
+	}
+
+	if w.spool != nil {
+		w.replay()
+		if w.spool.empty() && w.deliver(chunk) == nil {
+			return nil
+		}
+		return w.spoolChunk(chunk)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := w.deliver(chunk)
+		if err == nil || attempt >= w.retries {
+			return err
+		}
+		atomic.AddUint64(&w.stats.Retries, 1)
+		time.Sleep(time.Until(w.retryAt))
+	}
+}
+
+// spoolChunk 将发送失败的批次写入磁盘缓冲
+func (w *forwardSink) spoolChunk(chunk []byte) error {
+	dropped, err := w.spool.append(chunk)
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "Forward spool is full, dropped %d oldest batches\n", dropped)
+	}
+	if err != nil {
+		return fmt.Errorf("spool forward batch failed: %w", err)
+	}
+	return errBatchSpooled
+}
+
+// replay 按顺序重发磁盘缓冲中的批次，遇到失败时停止
+func (w *forwardSink) replay() {
+	for w.spool != nil && !w.spool.empty() {
+		chunk, err := w.spool.peek()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read spooled forward batch: %v\n", err)
+			return
+		}
+		if chunk == nil || w.deliver(chunk) != nil {
+			return
+		}
+		w.spool.advance()
+		atomic.AddUint64(&w.stats.Batches, 1)
+		atomic.AddUint64(&w.stats.Sent, uint64(binary.BigEndian.Uint32(chunk)))
+	}
+}
+
+// deliver 按模式包装批次后发送一次，要求确认时等待 agent 返回相同的 chunk ID
+// 连接失败后在退避时间内直接返回错误，避免每一批都等待连接超时
+func (w *forwardSink) deliver(chunk []byte) error {
+	if w.conn == nil {
+		if time.Now().Before(w.retryAt) {
+			return errors.New("forward agent unavailable")
+		}
+		conn, err := net.DialTimeout(w.network, w.address, networkDialTimeout)
+		if err != nil {
+			w.retryAt = time.Now().Add(w.backoff.duration())
+			return err
+		}
+		w.conn, w.reader = conn, bufio.NewReader(conn)
+	}
+
+	msg, chunkID, err := w.encode(chunk)
+	if err != nil {
+		return err
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+	if _, err = w.conn.Write(msg); err == nil && w.requireAck {
+		err = w.readAck(chunkID)
+	}
+	if err != nil {
+		w.disconnect()
+		w.retryAt = time.Now().Add(w.backoff.duration())
+		return err
+	}
+	w.backoff.reset()
+	return nil
+}
+
+// encode 将批次包装为 forward 协议消息，返回消息和用于确认的 chunk ID
+func (w *forwardSink) encode(chunk []byte) ([]byte, string, error) {
+	count := int(binary.BigEndian.Uint32(chunk))
+	entries := chunk[4:]
+
+	option := map[string]interface{}{"size": count}
+	var chunkID string
+	if w.requireAck {
+		var id [16]byte
+		if _, err := rand.Read(id[:]); err != nil {
+			return nil, "", err
+		}
+		chunkID = base64.StdEncoding.EncodeToString(id[:])
+		option["chunk"] = chunkID
+	}
+
+	b := appendMsgpackArrayHeader(make([]byte, 0, len(entries)+64), 3)
+	b = appendMsgpackString(b, w.tag)
+	switch w.mode {
+	case ForwardModePackedForward:
+		b = appendMsgpackBin(b, entries)
+	case ForwardModeCompressedPackedForward:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(entries); err != nil {
+			return nil, "", err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, "", err
+		}
+		b = appendMsgpackBin(b, buf.Bytes())
+		option["compressed"] = "gzip"
+	default:
+		b = appendMsgpackArrayHeader(b, count)
+		b = append(b, entries...)
+	}
+	b = appendMsgpackValue(b, option)
+	return b, chunkID, nil
+}
+
+// readAck 读取 agent 返回的 {"ack": chunk}
+func (w *forwardSink) readAck(chunkID string) error {
+	_ = w.conn.SetReadDeadline(time.Now().Add(w.ackTimeout))
+	v, err := decodeMsgpack(w.reader)
+	if err != nil {
+		return fmt.Errorf("read forward ack failed: %w", err)
+	}
+	if m, ok := v.(map[string]interface{}); !ok || m["ack"] != chunkID {
+		return fmt.Errorf("unexpected forward ack: %v", v)
+	}
+	return nil
+}
+
+func (w *forwardSink) disconnect() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn, w.reader = nil, nil
+	}
+}
+
+func (w *forwardSink) sinkName() string {
+	return "forward"
+}
+
+// Close 实现 io.Closer 接口，发送剩余的条目，未能发送的批次保留在磁盘缓冲中
+func (w *forwardSink) Close() error {
+	err := w.batcher.Close()
+	if w.spool != nil {
+		_ = w.spool.Close()
+	}
+	w.disconnect()
+	return err
+}
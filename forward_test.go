@@ -0,0 +1,329 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fluentEntry 解码后的 [time, record]
+type fluentEntry struct {
+	time   time.Time
+	record map[string]interface{}
+}
+
+// fakeFluent 模拟 Fluent Bit 的 forward 输入，按 option 中的 chunk 返回确认
+type fakeFluent struct {
+	ln net.Listener
+
+	mu      sync.Mutex
+	tags    []string
+	options []map[string]interface{}
+	entries []fluentEntry
+}
+
+func newFakeFluent(t *testing.T, addr string) *fakeFluent {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeFluent{ln: ln}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(t, c)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return f
+}
+
+func (f *fakeFluent) serve(t *testing.T, c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		v, err := decodeMsgpack(r)
+		if err != nil {
+			return
+		}
+		msg, ok := v.([]interface{})
+		if !ok || len(msg) != 3 {
+			t.Errorf("unexpected message: %v", v)
+			return
+		}
+		option, _ := msg[2].(map[string]interface{})
+
+		var raw []interface{}
+		switch x := msg[1].(type) {
+		case []interface{}:
+			raw = x
+		case []byte:
+			if option["compressed"] == "gzip" {
+				zr, err := gzip.NewReader(bytes.NewReader(x))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if x, err = io.ReadAll(zr); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			er := bufio.NewReader(bytes.NewReader(x))
+			for {
+				e, err := decodeMsgpack(er)
+				if err != nil {
+					break
+				}
+				raw = append(raw, e)
+			}
+		}
+
+		f.mu.Lock()
+		f.tags = append(f.tags, msg[0].(string))
+		f.options = append(f.options, option)
+		for _, e := range raw {
+			pair := e.([]interface{})
+			ext := pair[0].(msgpackExt)
+			ts := time.Unix(int64(binary.BigEndian.Uint32(ext.Data[:4])), int64(binary.BigEndian.Uint32(ext.Data[4:])))
+			f.entries = append(f.entries, fluentEntry{time: ts, record: pair[1].(map[string]interface{})})
+		}
+		f.mu.Unlock()
+
+		if chunk, ok := option["chunk"]; ok {
+			c.Write(appendMsgpackValue(nil, map[string]interface{}{"ack": chunk}))
+		}
+	}
+}
+
+func (f *fakeFluent) snapshot() ([]string, []map[string]interface{}, []fluentEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.tags...), append([]map[string]interface{}(nil), f.options...), append([]fluentEntry(nil), f.entries...)
+}
+
+// waitEntries 等待 agent 收到 n 个条目
+func (f *fakeFluent) waitEntries(t *testing.T, n int) []fluentEntry {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, _, entries := f.snapshot(); len(entries) >= n {
+			return entries
+		}
+		if time.Now().After(deadline) {
+			_, _, entries := f.snapshot()
+			t.Fatalf("expected %d entries, got %d", n, len(entries))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func tempRoot(t *testing.T) string {
+	root, err := os.MkdirTemp("", "logger-ut-forward")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+	return root
+}
+
+// TestForwardSinkModes 测试各发送模式、标签、记录内容和确认
+func TestForwardSinkModes(t *testing.T) {
+	for _, mode := range []string{ForwardModeForward, ForwardModePackedForward, ForwardModeCompressedPackedForward} {
+		t.Run(mode, func(t *testing.T) {
+			f := newFakeFluent(t, "127.0.0.1:0")
+			l := newSinkTestLogger(t, func(s *Settings) {
+				s.LogNameBase = "fwd"
+				s.DisableCaller = false
+				s.Forward = &ForwardSettings{
+					Address:       f.ln.Addr().String(),
+					TagPrefix:     "app",
+					Mode:          mode,
+					RequireAck:    true,
+					BatchSettings: BatchSettings{BatchInterval: time.Hour},
+				}
+			})
+			defer l.Close()
+
+			start := time.Now()
+			l.WithField("user", "alice").WithField("attempt", 3).WithField("msg", "shadow").Warn("first")
+			l.Info("second")
+			flushLogger(t, l)
+
+			tags, options, entries := f.snapshot()
+			if len(tags) != 1 || tags[0] != "app.fwd" {
+				t.Fatalf("tags = %v", tags)
+			}
+			if options[0]["size"] != int64(2) || options[0]["chunk"] == nil {
+				t.Errorf("option = %v", options[0])
+			}
+			if len(entries) != 2 {
+				t.Fatalf("expected 2 entries, got %d", len(entries))
+			}
+
+			rec := entries[0].record
+			if rec["msg"] != "first" || rec["level"] != "warning" || rec["user"] != "alice" ||
+				rec["attempt"] != int64(3) || rec["fields.msg"] != "shadow" {
+				t.Errorf("unexpected record: %v", rec)
+			}
+			if file, _ := rec["file"].(string); !strings.Contains(file, "forward_test.go:") || rec["func"] == nil {
+				t.Errorf("caller missing: %v", rec)
+			}
+			if d := entries[0].time.Sub(start); d < -time.Second || d > time.Minute {
+				t.Errorf("unexpected event time: %v", entries[0].time)
+			}
+			if entries[1].record["msg"] != "second" {
+				t.Errorf("unexpected order: %v", entries)
+			}
+
+			if stats := l.DeliveryStats()["forward"]; stats.Sent != 2 || stats.Batches != 1 || stats.Spooled != 0 {
+				t.Errorf("stats = %+v", stats)
+			}
+		})
+	}
+}
+
+// TestForwardSinkAgentRestart 测试 agent 不可用期间的批次写入磁盘缓冲，恢复后按顺序重发，
+// 进程重启后也会继续重发
+func TestForwardSinkAgentRestart(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	root := tempRoot(t)
+	fs := &ForwardSettings{
+		Address:    addr,
+		RequireAck: true,
+		BatchSettings: BatchSettings{
+			BatchSize:     2,
+			BatchInterval: 10 * time.Millisecond,
+			MinBackoff:    10 * time.Millisecond,
+			MaxBackoff:    20 * time.Millisecond,
+		},
+	}
+
+	// agent 未启动时关闭日志器，批次保留在缓冲中
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogRootFPath = root
+		s.LogNameBase = "fwd"
+		s.DisableCaller = false
+		s.Forward = fs
+	})
+	for _, msg := range []string{"a", "b", "c"} {
+		l.Info(msg)
+	}
+	flushLogger(t, l)
+	if stats := l.DeliveryStats()["forward"]; stats.Spooled != 3 || stats.Sent != 0 {
+		t.Errorf("stats while agent is down = %+v", stats)
+	}
+	l.Close()
+
+	l = newSinkTestLogger(t, func(s *Settings) {
+		s.LogRootFPath = root
+		s.LogNameBase = "fwd"
+		s.DisableCaller = false
+		s.Forward = fs
+	})
+	defer l.Close()
+	l.Info("d")
+	flushLogger(t, l)
+
+	f := newFakeFluent(t, addr)
+	l.Info("e")
+	entries := f.waitEntries(t, 5)
+	for i, want := range []string{"a", "b", "c", "d", "e"} {
+		if entries[i].record["msg"] != want {
+			t.Fatalf("entry %d = %v, want %s", i, entries[i].record["msg"], want)
+		}
+	}
+	// 统计在收到确认后才更新，可能晚于 agent 收到条目
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := l.DeliveryStats()["forward"]
+		if stats.Sent == 5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stats after recovery = %+v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestForwardSinkNoSpool 测试未开启缓冲时按 MaxRetries 重试后计为失败
+func TestForwardSinkNoSpool(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	retries := 2
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "fwd"
+		s.DisableCaller = false
+		s.Forward = &ForwardSettings{
+			Address:      addr,
+			DisableSpool: true,
+			BatchSettings: BatchSettings{
+				MaxRetries: &retries,
+				MinBackoff: time.Millisecond,
+				MaxBackoff: time.Millisecond,
+			},
+		}
+	})
+	defer l.Close()
+	l.Info("lost")
+	flushLogger(t, l)
+
+	if stats := l.DeliveryStats()["forward"]; stats.Failed != 1 || stats.Retries != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestForwardSettingsYAML 测试 YAML 配置和参数校验
+func TestForwardSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+forward:
+  address: 127.0.0.1:24224
+  tag_prefix: app
+  mode: packed_forward
+  require_ack: true
+  ack_timeout: 2s
+  batch_size: 50
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := s.Forward
+	if f == nil || f.Address != "127.0.0.1:24224" || f.TagPrefix != "app" || f.Mode != ForwardModePackedForward ||
+		!f.RequireAck || f.AckTimeout != 2*time.Second || f.BatchSize != 50 {
+		t.Fatalf("Forward = %+v", f)
+	}
+
+	for _, bad := range []*ForwardSettings{
+		{Network: "udp"},
+		{Mode: "message"},
+		{AckTimeout: -time.Second},
+	} {
+		settings := NewSettings()
+		settings.Forward = bad
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
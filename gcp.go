@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Cloud Logging 结构化日志中由日志代理解析的特殊字段
+	gcpSourceLocationKey = "logging.googleapis.com/sourceLocation"
+	gcpTraceKey          = "logging.googleapis.com/trace"
+	gcpSpanIDKey         = "logging.googleapis.com/spanId"
+)
+
+// GCPFormatter 输出 Google Cloud Logging（Stackdriver）结构化日志的 JSON，
+// 日志代理会将 severity、message、time 和 logging.googleapis.com/* 字段映射到 LogEntry，其余字段放入 jsonPayload
+// 输出格式：{"severity":"ERROR","message":"request failed","time":"2026-01-02T03:04:05.000000006Z","logging.googleapis.com/trace":"projects/p/traces/4bf9...","user":"alice"}
+//   - 调用者写入 logging.googleapis.com/sourceLocation，line 按 LogEntry 的定义为字符串
+//   - 追踪上下文依次取自 trace_id/span_id 字段、ContextWithTrace 和 TraceExtractor
+//   - 字段与上述键同名时加 fields. 前缀，error 类型的值输出为错误信息
+type GCPFormatter struct {
+	ProjectID      string         // 设置后 trace 输出为 projects/<ProjectID>/traces/<trace ID>，否则只输出 trace ID
+	DisableCaller  bool           // 是否禁用调用者信息
+	TraceExtractor TraceExtractor // 从条目的 context 中提取追踪上下文，可为 nil
+}
+
+// gcpSourceLocation logging.googleapis.com/sourceLocation 的结构
+type gcpSourceLocation struct {
+	File     string `json:"file"`
+	Line     string `json:"line"`
+	Function string `json:"function,omitempty"`
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *GCPFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	doc := map[string]interface{}{}
+	if !f.DisableCaller && entry.HasCaller() {
+		doc[gcpSourceLocationKey] = gcpSourceLocation{
+			File:     entry.Caller.File,
+			Line:     strconv.Itoa(entry.Caller.Line),
+			Function: entry.Caller.Function,
+		}
+	}
+
+	traceID, spanID := entryTrace(entry, f.TraceExtractor)
+	if traceID != "" {
+		if f.ProjectID != "" && !strings.HasPrefix(traceID, "projects/") {
+			doc[gcpTraceKey] = "projects/" + f.ProjectID + "/traces/" + traceID
+		} else {
+			doc[gcpTraceKey] = traceID
+		}
+	}
+	if spanID != "" {
+		doc[gcpSpanIDKey] = spanID
+	}
+
+	for k, v := range entry.Data {
+		switch k {
+		case TraceIDFieldKey, SpanIDFieldKey:
+			// 已写入 trace 和 spanId
+			continue
+		case "severity", "message", "time", gcpSourceLocationKey, gcpTraceKey, gcpSpanIDKey:
+			k = "fields." + k
+		}
+		if err, ok := v.(error); ok {
+			v = fieldString(err)
+		}
+		doc[k] = v
+	}
+
+	head, err := json.Marshal(struct {
+		Severity string `json:"severity"`
+		Message  string `json:"message"`
+		Time     string `json:"time"`
+	}{gcpSeverity(entry.Level), entry.Message, entry.Time.UTC().Format(time.RFC3339Nano)})
+	if err != nil {
+		return nil, err
+	}
+
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+	if len(doc) == 0 {
+		b.Write(head)
+	} else {
+		rest, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal fields to JSON: %w", err)
+		}
+		b.Write(head[:len(head)-1])
+		b.WriteByte(',')
+		b.Write(rest[1:])
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// gcpSeverity 将 logrus 级别映射为 Cloud Logging 的 LogSeverity
+func gcpSeverity(level logrus.Level) string {
+	switch level {
+	case logrus.PanicLevel:
+		return "ALERT"
+	case logrus.FatalLevel:
+		return "CRITICAL"
+	case logrus.ErrorLevel:
+		return "ERROR"
+	case logrus.WarnLevel:
+		return "WARNING"
+	case logrus.InfoLevel:
+		return "INFO"
+	default:
+		return "DEBUG"
+	}
+}
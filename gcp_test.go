@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestGCPFormatter 测试 Cloud Logging 的级别、调用者和追踪字段
+func TestGCPFormatter(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Date(2026, 1, 2, 11, 4, 5, 6, time.FixedZone("CST", 8*3600))
+	entry.Level = logrus.WarnLevel
+	entry.Message = "slow request"
+	entry.Caller = &runtime.Frame{File: "/src/app/main.go", Line: 42, Function: "main.handle"}
+	entry.Logger.ReportCaller = true
+	entry.Data = logrus.Fields{
+		"user":          "alice",
+		"severity":      "dup",
+		"error":         errors.New("timeout"),
+		SpanIDFieldKey:  "00f067aa0ba902b7",
+		"latency_ms":    1500,
+		"request.attrs": map[string]int{"a": 1},
+	}
+	entry.Context = ContextWithTrace(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736", "ffffffffffffffff")
+
+	b, err := (&GCPFormatter{ProjectID: "my-proj"}).Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"severity":"WARNING","message":"slow request","time":"2026-01-02T03:04:05.000000006Z",`; !strings.HasPrefix(string(b), want) {
+		t.Errorf("output should start with %s, got %s", want, b)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		gcpTraceKey:       "projects/my-proj/traces/4bf92f3577b34da6a3ce929d0e0e4736",
+		gcpSpanIDKey:      "00f067aa0ba902b7",
+		"user":            "alice",
+		"fields.severity": "dup",
+		"error":           "timeout",
+		"latency_ms":      float64(1500),
+	}
+	for k, w := range want {
+		if doc[k] != w {
+			t.Errorf("%s = %#v, want %#v", k, doc[k], w)
+		}
+	}
+	loc, _ := doc[gcpSourceLocationKey].(map[string]interface{})
+	if loc["file"] != "/src/app/main.go" || loc["line"] != "42" || loc["function"] != "main.handle" {
+		t.Errorf("unexpected sourceLocation %v", doc[gcpSourceLocationKey])
+	}
+	if _, ok := doc[SpanIDFieldKey]; ok {
+		t.Error("span_id should not be repeated")
+	}
+
+	// 使用 TraceExtractor，未设置项目 ID 时只输出 trace ID
+	entry = logrus.NewEntry(logrus.New())
+	entry.Level = logrus.DebugLevel
+	entry.Context = context.Background()
+	f := &GCPFormatter{TraceExtractor: func(context.Context) (string, string) { return "abc", "" }}
+	b, err = f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = nil
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["severity"] != "DEBUG" || doc[gcpTraceKey] != "abc" || doc[gcpSpanIDKey] != nil || doc[gcpSourceLocationKey] != nil {
+		t.Errorf("unexpected output %s", b)
+	}
+}
+
+// TestGCPSeverity 测试 logrus 级别到 LogSeverity 的映射
+func TestGCPSeverity(t *testing.T) {
+	want := map[logrus.Level]string{
+		logrus.TraceLevel: "DEBUG",
+		logrus.DebugLevel: "DEBUG",
+		logrus.InfoLevel:  "INFO",
+		logrus.WarnLevel:  "WARNING",
+		logrus.ErrorLevel: "ERROR",
+		logrus.FatalLevel: "CRITICAL",
+		logrus.PanicLevel: "ALERT",
+	}
+	for level, w := range want {
+		if got := gcpSeverity(level); got != w {
+			t.Errorf("gcpSeverity(%v) = %s, want %s", level, got, w)
+		}
+	}
+
+	s, err := parseSettingsYAML([]byte("formatter_type: gcp\ngcp_project_id: my-proj\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := (&FormatterFactory{}).CreateFormatter(s).(*GCPFormatter)
+	if !ok || f.ProjectID != "my-proj" {
+		t.Errorf("unexpected formatter %#v", f)
+	}
+	if out := formatWithFields(t, f, nil); out != `{"severity":"INFO","message":"hello","time":"2026-01-02T03:04:05Z"}`+"\n" {
+		t.Errorf("unexpected output %s", out)
+	}
+}
+
+// TestGCPFormatterNilError 测试值为 nil 指针的 error 字段不会 panic
+func TestGCPFormatterNilError(t *testing.T) {
+	var typedNil *otlpTestError
+	entry := logrus.NewEntry(logrus.New())
+	entry.Data = logrus.Fields{"error": typedNil}
+	b, err := (&GCPFormatter{DisableCaller: true}).Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["error"] != "<nil>" {
+		t.Errorf("unexpected output %s", b)
+	}
+}
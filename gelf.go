@@ -0,0 +1,312 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// GELF UDP 消息的压缩方式
+	GELFCompressGzip = "gzip" // 默认
+	GELFCompressZlib = "zlib"
+	GELFCompressNone = "none"
+
+	gelfChunkSizeDef    = 1420
+	gelfChunkHeaderSize = 12 // 2 字节魔数 + 8 字节消息 ID + 序号 + 总数
+	gelfMaxChunks       = 128
+)
+
+// GELFSettings Graylog GELF 1.1 输出配置
+type GELFSettings struct {
+	Network     string // "udp"（默认）或 "tcp"
+	Address     string // Graylog GELF 输入地址，如 "graylog:12201"
+	Host        string // host 字段（默认主机名）
+	Compression string // UDP 压缩方式："gzip"（默认）、"zlib"、"none"，TCP 不压缩
+	ChunkSize   int    // UDP 单个数据报的最大字节数（默认 1420），超出时分块发送
+
+	// 以下仅用于 TCP，断线重连和磁盘缓冲与网络输出相同
+	TLS                   bool   // 是否使用 TLS
+	TLSCAFile             string // 用于校验服务端证书的 CA 文件（PEM）
+	TLSInsecureSkipVerify bool   // 是否跳过证书校验，仅用于测试
+	SpoolDir              string // 缓冲目录（默认 <日志目录>/spool/<LogNameBase>-gelf）
+	MaxSpoolMB            int    // 缓冲上限(MB)（默认 100）
+	DisableSpool          bool   // 禁用磁盘缓冲
+}
+
+// validate 验证 GELF 输出配置
+func (s *GELFSettings) validate() error {
+	switch s.Network {
+	case "", "udp":
+		if s.TLS {
+			return fmt.Errorf("TLS is not supported over udp")
+		}
+	case "tcp":
+	default:
+		return fmt.Errorf("unknown gelf network: %s", s.Network)
+	}
+	if s.Address == "" {
+		return fmt.Errorf("gelf address cannot be empty")
+	}
+	switch s.Compression {
+	case "", GELFCompressGzip, GELFCompressZlib, GELFCompressNone:
+	default:
+		return fmt.Errorf("unknown gelf compression: %s", s.Compression)
+	}
+	if s.ChunkSize != 0 && s.ChunkSize <= gelfChunkHeaderSize {
+		return fmt.Errorf("gelf chunk size must be greater than %d", gelfChunkHeaderSize)
+	}
+	if s.ChunkSize < 0 || s.MaxSpoolMB < 0 {
+		return fmt.Errorf("gelf limits cannot be negative")
+	}
+	return nil
+}
+
+// newGELFSink 按传输方式创建 GELF 输出
+func newGELFSink(s *GELFSettings, settings *Settings) (sink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	f := &gelfFormatter{host: s.Host}
+	if f.host == "" {
+		f.host, _ = os.Hostname()
+	}
+
+	if s.Network == "tcp" {
+		// TCP 上每条消息以空字节结尾，不压缩
+		f.nullTerminated = true
+		ns := &NetworkSettings{
+			Network:               "tcp",
+			Address:               s.Address,
+			TLS:                   s.TLS,
+			TLSCAFile:             s.TLSCAFile,
+			TLSInsecureSkipVerify: s.TLSInsecureSkipVerify,
+			SpoolDir:              s.SpoolDir,
+			MaxSpoolMB:            s.MaxSpoolMB,
+			DisableSpool:          s.DisableSpool,
+		}
+		w, err := startNetworkSink(ns, f, filepath.Join(settings.logDir(), "spool", settings.LogNameBase+"-gelf"))
+		if err != nil {
+			return nil, err
+		}
+		return &gelfTCPSink{w}, nil
+	}
+
+	w := &gelfUDPSink{
+		formatter:   f,
+		compression: s.Compression,
+		chunkSize:   s.ChunkSize,
+	}
+	if w.compression == "" {
+		w.compression = GELFCompressGzip
+	}
+	if w.chunkSize == 0 {
+		w.chunkSize = gelfChunkSizeDef
+	}
+	conn, err := net.DialTimeout("udp", s.Address, networkDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// gelfFormatter 将条目格式化为 GELF 1.1 JSON
+// 字段作为附加字段（名称加 "_" 前缀），调用者信息写入 _file、_line 和 _function，
+// 此时与之冲突的 file、line、function 字段加 fields. 前缀，与 forward 输出的处理一致
+type gelfFormatter struct {
+	host           string
+	nullTerminated bool
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *gelfFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	m := make(map[string]interface{}, len(entry.Data)+8)
+	for k, v := range entry.Data {
+		name := gelfFieldName(k)
+		if entry.HasCaller() && (name == "_file" || name == "_line" || name == "_function") {
+			name = gelfFieldName("fields." + k)
+		}
+		m[name] = gelfValue(v)
+	}
+	if entry.HasCaller() {
+		m["_file"] = entry.Caller.File
+		m["_line"] = entry.Caller.Line
+		m["_function"] = entry.Caller.Function
+	}
+
+	short := strings.TrimSpace(entry.Message)
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = strings.TrimSpace(short[:i])
+		m["full_message"] = entry.Message
+	}
+	if short == "" {
+		// short_message 为必填且不能为空
+		short = "-"
+	}
+	m["version"] = "1.1"
+	m["host"] = f.host
+	m["short_message"] = short
+	m["timestamp"] = float64(entry.Time.UnixNano()/int64(time.Millisecond)) / 1000
+	m["level"] = syslogSeverity(entry.Level)
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if f.nullTerminated {
+		b = append(b, 0)
+	}
+	return b, nil
+}
+
+// gelfFieldName 附加字段名只能包含字母、数字、下划线、点和连字符，且不能为 _id
+func gelfFieldName(k string) string {
+	b := []byte(k)
+	for i, c := range b {
+		if !(c == '_' || c == '.' || c == '-' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	if string(b) == "id" {
+		return "__id"
+	}
+	return "_" + string(b)
+}
+
+// gelfValue 附加字段的值只能是字符串或数字
+func gelfValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if _, ok := v.(fmt.Stringer); !ok {
+			return v
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return v
+		}
+	}
+	return fieldString(v)
+}
+
+// gelfTCPSink 通过 TCP 发送以空字节分隔的 GELF 消息
+// 单独定义类型，避免 NetworkStats 将其当作网络输出
+type gelfTCPSink struct {
+	*networkSink
+}
+
+// gelfUDPSink 通过 UDP 发送 GELF 消息，压缩后超过 ChunkSize 时按 GELF 分块格式拆分
+type gelfUDPSink struct {
+	formatter   *gelfFormatter
+	compression string
+	chunkSize   int
+
+	mu   sync.Mutex
+	conn net.Conn
+	buf  bytes.Buffer
+}
+
+// Send 实现 sink 接口
+func (w *gelfUDPSink) Send(entry *logrus.Entry) error {
+	msg, err := w.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return fmt.Errorf("gelf sink is closed")
+	}
+	if msg, err = w.compress(msg); err != nil {
+		return err
+	}
+	if len(msg) <= w.chunkSize {
+		_, err = w.conn.Write(msg)
+		return err
+	}
+	return w.writeChunks(msg)
+}
+
+// compress 按配置压缩消息，Graylog 根据魔数自动识别压缩方式
+func (w *gelfUDPSink) compress(msg []byte) ([]byte, error) {
+	var zw io.WriteCloser
+	w.buf.Reset()
+	switch w.compression {
+	case GELFCompressGzip:
+		zw = gzip.NewWriter(&w.buf)
+	case GELFCompressZlib:
+		zw = zlib.NewWriter(&w.buf)
+	default:
+		return msg, nil
+	}
+	if _, err := zw.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// writeChunks 按 GELF 分块格式发送：0x1e 0x0f、8 字节消息 ID、序号和总块数，最多 128 块
+func (w *gelfUDPSink) writeChunks(msg []byte) error {
+	size := w.chunkSize - gelfChunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return fmt.Errorf("gelf message too large: %d bytes", len(msg))
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		binary.BigEndian.PutUint64(id[:], uint64(time.Now().UnixNano()))
+	}
+
+	chunk := make([]byte, 0, w.chunkSize)
+	for i := 0; i < count; i++ {
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, msg[i*size:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close 实现 io.Closer 接口
+func (w *gelfUDPSink) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readGELFDatagram 读取一个 UDP 数据报，分块消息读取全部分块后按序号拼接，再按魔数解压
+func readGELFDatagram(t *testing.T, conn net.PacketConn) (map[string]interface{}, int) {
+	buf := make([]byte, 65536)
+	var (
+		chunks = make(map[int][]byte)
+		total  = 1
+	)
+	for len(chunks) < total {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := append([]byte(nil), buf[:n]...)
+		if len(p) < 2 || p[0] != 0x1e || p[1] != 0x0f {
+			chunks[0] = p
+			break
+		}
+		chunks[int(p[10])] = p[gelfChunkHeaderSize:]
+		total = int(p[11])
+	}
+
+	keys := make([]int, 0, len(chunks))
+	for k := range chunks {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	var msg []byte
+	for _, k := range keys {
+		msg = append(msg, chunks[k]...)
+	}
+
+	var r io.Reader = bytes.NewReader(msg)
+	switch {
+	case len(msg) > 1 && msg[0] == 0x1f && msg[1] == 0x8b:
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case len(msg) > 0 && msg[0] == 0x78:
+		zr, err := zlib.NewReader(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	}
+
+	var v map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		t.Fatalf("invalid GELF message: %v", err)
+	}
+	return v, len(chunks)
+}
+
+// TestGELFUDP 测试 GELF 字段映射和 gzip 压缩
+func TestGELFUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.DisableCaller = false
+		s.GELF = &GELFSettings{Address: conn.LocalAddr().String(), Host: "web-1"}
+	})
+	l.WithFields(map[string]interface{}{
+		"user":     "alice",
+		"attempts": 3,
+		"ok":       true,
+		"id":       42,
+		"bad key":  "x",
+		"file":     "invoice.pdf",
+		"line":     "L2",
+	}).Warn("payment failed\nstack line 1")
+
+	v, chunks := readGELFDatagram(t, conn)
+	if chunks != 1 {
+		t.Errorf("small message should not be chunked, got %d chunks", chunks)
+	}
+	if v["version"] != "1.1" || v["host"] != "web-1" || v["short_message"] != "payment failed" ||
+		v["full_message"] != "payment failed\nstack line 1" || v["level"] != float64(4) {
+		t.Errorf("unexpected message: %v", v)
+	}
+	if v["_user"] != "alice" || v["_attempts"] != float64(3) || v["_ok"] != "true" || v["__id"] != float64(42) || v["_bad_key"] != "x" {
+		t.Errorf("unexpected additional fields: %v", v)
+	}
+	if _, ok := v["_id"]; ok {
+		t.Error("_id is reserved by GELF")
+	}
+	if !strings.HasSuffix(v["_file"].(string), "gelf_test.go") || v["_line"].(float64) <= 0 {
+		t.Errorf("unexpected caller: file=%v line=%v", v["_file"], v["_line"])
+	}
+	// 与调用者信息冲突的字段加 fields. 前缀
+	if v["_fields.file"] != "invoice.pdf" || v["_fields.line"] != "L2" {
+		t.Errorf("colliding fields should be prefixed: %v", v)
+	}
+	if ts := v["timestamp"].(float64); ts < float64(time.Now().Add(-time.Minute).Unix()) {
+		t.Errorf("unexpected timestamp: %v", ts)
+	}
+}
+
+// TestGELFUDPChunking 测试超过分块大小的消息按 GELF 分块格式发送
+func TestGELFUDPChunking(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.DisableCaller = false
+		s.GELF = &GELFSettings{
+			Address:     conn.LocalAddr().String(),
+			Compression: GELFCompressZlib,
+			ChunkSize:   200,
+		}
+	})
+	// 随机性不足的内容压缩率过高，使用较长且不重复的内容确保需要分块
+	var b strings.Builder
+	for i := 0; b.Len() < 4000; i++ {
+		b.WriteString(time.Duration(i * 7919).String())
+	}
+	l.Info(b.String())
+
+	v, chunks := readGELFDatagram(t, conn)
+	if chunks < 2 {
+		t.Errorf("expected a chunked message, got %d chunks", chunks)
+	}
+	if v["short_message"] != b.String() {
+		t.Error("reassembled message does not match")
+	}
+}
+
+// TestGELFTCP 测试 TCP 上以空字节分隔且不压缩
+func TestGELFTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	messages := make(chan map[string]interface{}, 16)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		r := bufio.NewReader(c)
+		for {
+			b, err := r.ReadBytes(0)
+			if err != nil {
+				return
+			}
+			var v map[string]interface{}
+			if json.Unmarshal(b[:len(b)-1], &v) == nil {
+				messages <- v
+			}
+		}
+	}()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.DisableCaller = false
+		s.GELF = &GELFSettings{Network: "tcp", Address: ln.Addr().String(), DisableSpool: true}
+	})
+	l.WithField("module", "db").Error("first")
+	l.Info("second")
+
+	for _, want := range []string{"first", "second"} {
+		select {
+		case v := <-messages:
+			if v["short_message"] != want {
+				t.Errorf("got %v, want %s", v["short_message"], want)
+			}
+			if want == "first" && (v["_module"] != "db" || v["level"] != float64(3)) {
+				t.Errorf("unexpected message: %v", v)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", want)
+		}
+	}
+	if stats := l.NetworkStats(); stats.Sent != 0 {
+		t.Errorf("GELF should not be reported as the network sink: %+v", stats)
+	}
+}
+
+// TestGELFSettingsYAML 测试 YAML 配置和参数校验
+func TestGELFSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+gelf:
+  network: tcp
+  address: graylog:12201
+  host: web-1
+  chunk_size: 8192
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := s.GELF
+	if g == nil || g.Network != "tcp" || g.Address != "graylog:12201" || g.Host != "web-1" || g.ChunkSize != 8192 {
+		t.Fatalf("GELF = %+v", g)
+	}
+
+	for _, bad := range []*GELFSettings{
+		{Address: ""},
+		{Network: "sctp", Address: "graylog:12201"},
+		{Address: "graylog:12201", Compression: "lz4"},
+		{Address: "graylog:12201", ChunkSize: 10},
+		{Address: "graylog:12201", TLS: true},
+	} {
+		settings := NewSettings()
+		settings.GELF = bad
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
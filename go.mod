@@ -4,6 +4,7 @@ go 1.16
 
 require (
 	github.com/jonboulle/clockwork v0.1.0 // indirect
+	github.com/klauspost/compress v1.15.15
 	github.com/lestrrat-go/file-rotatelogs v2.4.0+incompatible
 	github.com/lestrrat-go/strftime v1.0.5 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
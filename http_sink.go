@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HTTPSettings HTTP 批量输出配置，以换行分隔的 JSON（NDJSON）作为请求体
+type HTTPSettings struct {
+	URL           string            // 收集端地址
+	Headers       map[string]string // 附加的请求头，如认证信息
+	FormatterType string            // 每行使用的格式器类型（默认 "json"）
+	DisableGzip   bool              // 禁用请求体 gzip 压缩
+	Timeout       time.Duration     // 单次请求超时（默认 10s）
+	BatchSettings                   // 批量与重试配置
+}
+
+// validate 验证 HTTP 输出配置
+func (s *HTTPSettings) validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid http url: %q", s.URL)
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("http timeout cannot be negative")
+	}
+	return s.BatchSettings.validate()
+}
+
+// httpSink 将条目攒批后以 NDJSON 格式 POST 到收集端
+type httpSink struct {
+	formatter logrus.Formatter
+	poster    *httpPoster
+	*batcher
+}
+
+func newHTTPSink(s *HTTPSettings, settings *Settings) (*httpSink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	formatterType := s.FormatterType
+	if formatterType == "" {
+		formatterType = FormatterTypeJSON
+	}
+	w := &httpSink{formatter: sinkFormatter(settings, formatterType)}
+	w.batcher = newBatcher(s.BatchSettings, w.send, nil)
+	w.poster = newHTTPPoster(s.URL, s.Headers, !s.DisableGzip, s.Timeout, s.BatchSettings, &w.batcher.stats)
+	return w, nil
+}
+
+// Send 实现 sink 接口
+func (w *httpSink) Send(entry *logrus.Entry) error {
+	b, err := w.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	// 格式器可能复用缓冲区，必须复制
+	return w.add(batchRecord{data: append([]byte(nil), b...)})
+}
+
+// send 将一批条目拼接为 NDJSON 后发送
+func (w *httpSink) send(batch []batchRecord) error {
+	var body bytes.Buffer
+	for _, rec := range batch {
+		body.Write(rec.data)
+		if n := len(rec.data); n == 0 || rec.data[n-1] != '\n' {
+			body.WriteByte('\n')
+		}
+	}
+	return w.poster.post(body.Bytes(), "application/x-ndjson")
+}
+
+func (w *httpSink) sinkName() string {
+	return "http"
+}
@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector 记录收到的 NDJSON 请求
+type collector struct {
+	mu       sync.Mutex
+	batches  [][]map[string]interface{}
+	headers  []http.Header
+	statuses []int // 依次返回的状态码，用完后返回 200
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.statuses) > 0 {
+		status := c.statuses[0]
+		c.statuses = c.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+
+	var batch []map[string]interface{}
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var v map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		batch = append(batch, v)
+	}
+	c.batches = append(c.batches, batch)
+	c.headers = append(c.headers, r.Header.Clone())
+}
+
+func (c *collector) snapshot() ([][]map[string]interface{}, []http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([][]map[string]interface{}(nil), c.batches...), append([]http.Header(nil), c.headers...)
+}
+
+func flushLogger(t *testing.T, l *Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := l.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestHTTPSinkBatching 测试按数量攒批、gzip 压缩和自定义请求头
+func TestHTTPSinkBatching(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.HTTP = &HTTPSettings{
+			URL:           srv.URL,
+			Headers:       map[string]string{"Authorization": "Bearer token"},
+			BatchSettings: BatchSettings{BatchSize: 3, BatchInterval: time.Hour},
+		}
+	})
+	for i := 0; i < 7; i++ {
+		l.WithField("i", i).Info("event")
+	}
+	flushLogger(t, l)
+
+	batches, headers := c.snapshot()
+	if len(batches) != 3 || len(batches[0]) != 3 || len(batches[1]) != 3 || len(batches[2]) != 1 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	if batches[2][0]["i"] != float64(6) || batches[0][0]["msg"] != "event" {
+		t.Errorf("unexpected entries: %v", batches)
+	}
+	h := headers[0]
+	if h.Get("Authorization") != "Bearer token" || h.Get("Content-Encoding") != "gzip" || h.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+
+	stats := l.DeliveryStats()["http"]
+	if stats.Batches != 3 || stats.Sent != 7 || stats.Failed != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestHTTPSinkBatchBytes 测试按字节数攒批
+func TestHTTPSinkBatchBytes(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.HTTP = &HTTPSettings{
+			URL:           srv.URL,
+			DisableGzip:   true,
+			BatchSettings: BatchSettings{BatchBytes: 300, BatchInterval: time.Hour},
+		}
+	})
+	for i := 0; i < 4; i++ {
+		l.Info(strings.Repeat("x", 100))
+	}
+	flushLogger(t, l)
+
+	batches, headers := c.snapshot()
+	if len(batches) != 4 {
+		t.Errorf("expected one entry per batch, got %d batches", len(batches))
+	}
+	if headers[0].Get("Content-Encoding") != "" {
+		t.Error("body should not be gzipped")
+	}
+}
+
+// TestHTTPSinkRetry 测试 5xx 时重试，4xx 时不重试
+func TestHTTPSinkRetry(t *testing.T) {
+	c := &collector{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.HTTP = &HTTPSettings{
+			URL:           srv.URL,
+			BatchSettings: BatchSettings{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond},
+		}
+	})
+	l.Info("eventually delivered")
+	flushLogger(t, l)
+
+	stats := l.DeliveryStats()["http"]
+	if stats.Sent != 1 || stats.Retries != 2 || stats.Failed != 0 {
+		t.Errorf("after 5xx: stats = %+v", stats)
+	}
+
+	c.mu.Lock()
+	c.statuses = []int{http.StatusUnauthorized}
+	c.mu.Unlock()
+	l.Info("rejected")
+	flushLogger(t, l)
+
+	stats = l.DeliveryStats()["http"]
+	if stats.Failed != 1 || stats.Retries != 2 {
+		t.Errorf("after 4xx: stats = %+v", stats)
+	}
+}
+
+// TestHTTPSinkNoRetry 测试 MaxRetries 为 0 时失败后不重试
+func TestHTTPSinkNoRetry(t *testing.T) {
+	c := &collector{statuses: []int{http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	s, err := parseSettingsYAML([]byte("http:\n  url: " + srv.URL + "\n  max_retries: 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := s.HTTP.MaxRetries; r == nil || *r != 0 {
+		t.Fatalf("max_retries: 0 should be kept, got %v", r)
+	}
+	l := newSinkTestLogger(t, func(settings *Settings) {
+		settings.HTTP = s.HTTP
+	})
+	l.Info("not retried")
+	flushLogger(t, l)
+
+	stats := l.DeliveryStats()["http"]
+	if stats.Failed != 1 || stats.Retries != 0 {
+		t.Errorf("stats = %+v, want one failure without retries", stats)
+	}
+}
+
+// TestHTTPSinkInterval 测试到期后发送不足一批的条目
+func TestHTTPSinkInterval(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.HTTP = &HTTPSettings{
+			URL:           srv.URL,
+			BatchSettings: BatchSettings{BatchInterval: 20 * time.Millisecond},
+		}
+	})
+	l.Info("tick")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if batches, _ := c.snapshot(); len(batches) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("batch was not sent after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestHTTPSettingsYAML 测试 YAML 配置和参数校验
+func TestHTTPSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+http:
+  url: https://collector.example.com/ingest
+  headers:
+    Authorization: Bearer abc
+  batch_size: 500
+  batch_interval: 2s
+  max_retries: 5
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := s.HTTP
+	if h == nil || h.URL != "https://collector.example.com/ingest" || h.Headers["Authorization"] != "Bearer abc" ||
+		h.BatchSize != 500 || h.BatchInterval != 2*time.Second || h.MaxRetries == nil || *h.MaxRetries != 5 {
+		t.Fatalf("HTTP = %+v", h)
+	}
+
+	negative := -1
+	for _, bad := range []*HTTPSettings{
+		{URL: "collector:8080"},
+		{URL: "http://collector", BatchSettings: BatchSettings{BatchSize: -1}},
+		{URL: "http://collector", BatchSettings: BatchSettings{MaxRetries: &negative}},
+	} {
+		settings := NewSettings()
+		settings.HTTP = bad
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+)
+
+// journaldSocketDef systemd-journald 原生协议的默认套接字
+const journaldSocketDef = "/run/systemd/journal/socket"
+
+// JournaldSettings systemd-journald 输出配置
+type JournaldSettings struct {
+	Socket     string // 套接字路径（默认 /run/systemd/journal/socket）
+	Identifier string // SYSLOG_IDENTIFIER（默认使用 LogNameBase）
+}
+
+// journaldSink 通过 journald 原生协议发送条目
+// 每条日志是一个数据报，字段格式为 KEY=value\n；值包含换行时使用 KEY\n<64 位小端长度><value>\n。
+// 数据报超过套接字上限时，按协议将内容写入临时文件并传递文件描述符
+type journaldSink struct {
+	socket     string
+	identifier string
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+	buf  bytes.Buffer
+}
+
+func newJournaldSink(s *JournaldSettings, name string) (*journaldSink, error) {
+	w := &journaldSink{socket: s.Socket, identifier: s.Identifier}
+	if w.socket == "" {
+		w.socket = journaldSocketDef
+	}
+	if w.identifier == "" {
+		w.identifier = name
+	}
+
+	// 使用未连接的数据报套接字，journald 重启后无需重连
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: "", Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	w.conn = conn
+	return w, nil
+}
+
+// Send 实现 sink 接口
+func (w *journaldSink) Send(entry *logrus.Entry) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return errors.New("journald sink is closed")
+	}
+
+	w.buf.Reset()
+	w.format(&w.buf, entry)
+
+	addr := &net.UnixAddr{Name: w.socket, Net: "unixgram"}
+	_, err := w.conn.WriteToUnix(w.buf.Bytes(), addr)
+	if isMessageTooLarge(err) {
+		return journaldSendFD(w.conn, addr, w.buf.Bytes())
+	}
+	return err
+}
+
+// format 将条目编码为 journald 原生协议的字段
+func (w *journaldSink) format(b *bytes.Buffer, entry *logrus.Entry) {
+	writeJournalField(b, "MESSAGE", entry.Message)
+	writeJournalField(b, "PRIORITY", strconv.Itoa(syslogSeverity(entry.Level)))
+	writeJournalField(b, "SYSLOG_IDENTIFIER", w.identifier)
+	if entry.HasCaller() {
+		writeJournalField(b, "CODE_FILE", entry.Caller.File)
+		writeJournalField(b, "CODE_LINE", strconv.Itoa(entry.Caller.Line))
+		writeJournalField(b, "CODE_FUNC", entry.Caller.Function)
+	}
+
+	for _, k := range sortedKeys(entry.Data) {
+		name := journalFieldName(k)
+		if name == "" {
+			continue
+		}
+		writeJournalField(b, name, fieldString(entry.Data[k]))
+	}
+}
+
+// Close 实现 io.Closer 接口
+func (w *journaldSink) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// writeJournalField 写入一个字段，值包含换行时使用二进制安全的长度前缀格式
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	b.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	b.Write(size[:])
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journaldReservedFields 由日志器设置的字段，同名的用户字段加 F_ 前缀避免覆盖
+var journaldReservedFields = map[string]bool{
+	"MESSAGE": true, "PRIORITY": true, "SYSLOG_IDENTIFIER": true,
+	"CODE_FILE": true, "CODE_LINE": true, "CODE_FUNC": true,
+}
+
+// journalFieldName 将字段名转换为 journald 字段名：大写字母、数字和下划线，
+// 不能以下划线（journald 的受信字段）或数字开头，最长 64 个字符
+func journalFieldName(key string) string {
+	var b strings.Builder
+	for _, c := range strings.ToUpper(key) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	name := strings.TrimLeft(b.String(), "_")
+	if name == "" {
+		return ""
+	}
+	if (name[0] >= '0' && name[0] <= '9') || journaldReservedFields[name] {
+		name = "F_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// isMessageTooLarge 判断发送失败是否因为数据报超过套接字上限
+func isMessageTooLarge(err error) bool {
+	if err == nil {
+		return false
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return errno == syscall.EMSGSIZE || errno == syscall.ENOBUFS
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// journaldSendFD 将内容写入已删除的临时文件，再通过 SCM_RIGHTS 把文件描述符发送给 journald
+func journaldSendFD(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	f, err := os.CreateTemp("/dev/shm", "journal.*")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+	return err
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestJournaldSinkLargeEntry 测试超过数据报上限的条目通过文件描述符发送
+func TestJournaldSinkLargeEntry(t *testing.T) {
+	if _, err := os.Stat("/dev/shm"); err != nil {
+		t.Skip("/dev/shm not available")
+	}
+	conn, path := listenFakeJournal(t)
+
+	w, err := newJournaldSink(&JournaldSettings{Socket: path}, "big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	message := strings.Repeat("x", 8*1024*1024)
+	entry := logrus.NewEntry(logrus.New())
+	entry.Level = logrus.InfoLevel
+	entry.Message = message
+	if err := w.Send(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_, oobn, _, _, err := conn.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected one control message, got %v (%v)", msgs, err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected one fd, got %v (%v)", fds, err)
+	}
+
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := parseJournalFields(t, b); fields["MESSAGE"] != message || fields["SYSLOG_IDENTIFIER"] != "big" {
+		t.Errorf("unexpected fields from fd: MESSAGE len=%d SYSLOG_IDENTIFIER=%q", len(fields["MESSAGE"]), fields["SYSLOG_IDENTIFIER"])
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package logger
+
+import (
+	"errors"
+	"net"
+)
+
+var errJournaldFDUnsupported = errors.New("journald: passing large entries is not supported on this platform")
+
+// journaldSendFD 非 Linux 平台没有 journald，超过数据报上限的条目无法发送
+func journaldSendFD(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	return errJournaldFDUnsupported
+}
@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// parseJournalFields 解析 journald 原生协议的数据报
+func parseJournalFields(t *testing.T, b []byte) map[string]string {
+	fields := make(map[string]string)
+	for len(b) > 0 {
+		nl := bytes.IndexByte(b, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated field: %q", b)
+		}
+		line := b[:nl]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			b = b[nl+1:]
+			continue
+		}
+		// 二进制格式：KEY\n<64 位小端长度><value>\n
+		size := binary.LittleEndian.Uint64(b[nl+1 : nl+9])
+		fields[string(line)] = string(b[nl+9 : nl+9+int(size)])
+		b = b[nl+9+int(size)+1:]
+	}
+	return fields
+}
+
+// listenFakeJournal 在临时目录中创建模拟 journald 的数据报套接字
+func listenFakeJournal(t *testing.T) (*net.UnixConn, string) {
+	dir, err := os.MkdirTemp("", "logger-ut-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram not supported: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// TestJournaldSink 测试字段提升、PRIORITY 和调用者信息
+func TestJournaldSink(t *testing.T) {
+	conn, path := listenFakeJournal(t)
+
+	root, err := os.MkdirTemp("", "logger-ut-journald")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "api"
+	settings.DisableCaller = false
+	settings.Journald = &JournaldSettings{Socket: path}
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.WithFields(logrus.Fields{
+		"request-id": "r-1",
+		"_trusted":   "x",
+		"message":    "shadow",
+		"stack":      "line1\nline2",
+	}).Error("request failed")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64*1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := parseJournalFields(t, buf[:n])
+
+	want := map[string]string{
+		"MESSAGE":           "request failed",
+		"PRIORITY":          "3",
+		"SYSLOG_IDENTIFIER": "api",
+		"REQUEST_ID":        "r-1",
+		"TRUSTED":           "x",
+		"F_MESSAGE":         "shadow",
+		"STACK":             "line1\nline2",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("%s = %q, want %q", k, fields[k], v)
+		}
+	}
+	if !strings.HasSuffix(fields["CODE_FILE"], "journald_test.go") || fields["CODE_LINE"] == "" {
+		t.Errorf("CODE_FILE=%q CODE_LINE=%q, want caller in journald_test.go", fields["CODE_FILE"], fields["CODE_LINE"])
+	}
+}
+
+// TestJournalFieldName 测试字段名转换规则
+func TestJournalFieldName(t *testing.T) {
+	cases := map[string]string{
+		"user":                  "USER",
+		"http.status":           "HTTP_STATUS",
+		"__internal":            "INTERNAL",
+		"1st":                   "F_1ST",
+		"priority":              "F_PRIORITY",
+		"___":                   "",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	}
+	for in, want := range cases {
+		if got := journalFieldName(in); got != want {
+			t.Errorf("journalFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// TestJournaldSettingsYAML 测试 YAML 配置
+func TestJournaldSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte("journald:\n  identifier: worker\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Journald == nil || s.Journald.Identifier != "worker" || s.Journald.Socket != "" {
+		t.Errorf("Journald = %+v, want identifier worker with default socket", s.Journald)
+	}
+}
@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SetLevel 原地调整默认日志器的级别，不会重建文件写入器
+func SetLevel(level logrus.Level) {
+	getLoggerInternal().SetLevel(level)
+}
+
+// GetLevel 返回默认日志器当前的级别
+func GetLevel() logrus.Level {
+	return getLoggerInternal().GetLevel()
+}
+
+// LevelHandler 查询和修改日志级别的 HTTP 处理器
+// GET 返回当前级别，PUT 使用相同格式的请求体修改级别：{"level":"debug"}
+// Logger 为 nil 时作用于默认日志器
+type LevelHandler struct {
+	Logger *Logger
+}
+
+type levelPayload struct {
+	Level string `json:"level"`
+}
+
+type levelErrorPayload struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP 实现 http.Handler 接口
+func (h LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := h.Logger
+	if l == nil {
+		l = getLoggerInternal()
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+		level, err := logrus.ParseLevel(req.Level)
+		if err != nil {
+			writeLevelJSON(w, http.StatusBadRequest, levelErrorPayload{Error: err.Error()})
+			return
+		}
+		l.SetLevel(level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelJSON(w, http.StatusMethodNotAllowed, levelErrorPayload{Error: "only GET and PUT are supported"})
+		return
+	}
+
+	writeLevelJSON(w, http.StatusOK, levelPayload{Level: l.GetLevel().String()})
+}
+
+func writeLevelJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
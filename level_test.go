@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestSetLevelInPlace 测试调整级别不会替换日志器
+func TestSetLevelInPlace(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	testLogger := logrus.New()
+	testLogger.Out = &bytes.Buffer{}
+	l := &Logger{Logger: testLogger}
+	loggerMutex.Lock()
+	defaultLogger = l
+	loggerMutex.Unlock()
+
+	SetLevel(logrus.DebugLevel)
+	if GetLevel() != logrus.DebugLevel {
+		t.Errorf("GetLevel() = %v, want debug", GetLevel())
+	}
+	if Default() != l {
+		t.Error("SetLevel should not replace the default logger")
+	}
+
+	Debug("debug message")
+	if !strings.Contains(testLogger.Out.(*bytes.Buffer).String(), "debug message") {
+		t.Error("debug message should appear after SetLevel(DebugLevel)")
+	}
+}
+
+// TestLevelHandler 测试通过 HTTP 查询和修改级别
+func TestLevelHandler(t *testing.T) {
+	testLogger := logrus.New()
+	testLogger.Out = &bytes.Buffer{}
+	handler := LevelHandler{Logger: &Logger{Logger: testLogger}}
+
+	do := func(method, body string) (*httptest.ResponseRecorder, map[string]string) {
+		req := httptest.NewRequest(method, "/log/level", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var resp map[string]string
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: decode response failed: %v", method, err)
+		}
+		return rec, resp
+	}
+
+	rec, resp := do(http.MethodGet, "")
+	if rec.Code != http.StatusOK || resp["level"] != "info" {
+		t.Errorf("GET: code=%d resp=%v, want 200 info", rec.Code, resp)
+	}
+
+	rec, resp = do(http.MethodPut, `{"level":"debug"}`)
+	if rec.Code != http.StatusOK || resp["level"] != "debug" {
+		t.Errorf("PUT: code=%d resp=%v, want 200 debug", rec.Code, resp)
+	}
+	if testLogger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("logger level = %v, want debug", testLogger.GetLevel())
+	}
+
+	rec, resp = do(http.MethodPut, `{"level":"verbose"}`)
+	if rec.Code != http.StatusBadRequest || resp["error"] == "" {
+		t.Errorf("PUT invalid: code=%d resp=%v, want 400 with error", rec.Code, resp)
+	}
+	if testLogger.GetLevel() != logrus.DebugLevel {
+		t.Error("invalid level should not change the logger level")
+	}
+
+	rec, _ = do(http.MethodPost, `{"level":"info"}`)
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("POST: code=%d allow=%q, want 405", rec.Code, rec.Header().Get("Allow"))
+	}
+}
+
+// TestParseSettingsYAMLInvalidLevel 测试配置中无法识别的级别返回错误，而不是改为 info
+func TestParseSettingsYAMLInvalidLevel(t *testing.T) {
+	for _, c := range []struct{ yaml, want string }{
+		{"level: degub\n", "level"},
+		{"async_drop_level: nope\n", "async_drop_level"},
+		{"module_levels:\n  db: loud\n", `module_levels "db"`},
+		{"console_level: nope\n", "console_level"},
+		{"routes:\n  - name: err\n    level: eror\n", `route "err" level`},
+		{"routes:\n  - name: err\n    levels: [error, fatl]\n", `route "err" levels`},
+		{"loggers:\n  billing:\n    level: nope\n", `logger "billing" level`},
+	} {
+		_, err := parseSettingsYAML([]byte(c.yaml))
+		if err == nil || !strings.Contains(err.Error(), c.want) || !strings.Contains(err.Error(), "unknown level") {
+			t.Errorf("%q: got %v, want unknown level error for %s", c.yaml, err, c.want)
+		}
+	}
+
+	s, err := parseSettingsYAML([]byte("level: WARNING\n"))
+	if err != nil || s.Level != logrus.WarnLevel {
+		t.Errorf("level names should be case-insensitive, got %v, %v", s, err)
+	}
+}
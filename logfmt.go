@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// logfmt 格式器默认的键名
+	logfmtTimeKeyDef    = "time"
+	logfmtLevelKeyDef   = "level"
+	logfmtMessageKeyDef = "msg"
+	logfmtCallerKeyDef  = "caller"
+)
+
+// LogfmtFormatter 输出严格的 logfmt 格式，每行由空格分隔的 key=value 组成
+// 输出格式：time=2026-01-02T03:04:05.000Z level=info msg="user login" user=alice
+// 键中的空白、引号、= 和控制字符替换为 _，需要时值加引号并转义；
+// 字段与时间、级别、消息、调用者的键同名时加 fields. 前缀，与 logrus 的处理一致
+type LogfmtFormatter struct {
+	TimestampFormat  string   // 时间戳格式，默认 time.RFC3339Nano
+	DisableTimestamp bool     // 是否禁用时间戳
+	DisableLevel     bool     // 是否禁用日志级别
+	DisableCaller    bool     // 是否禁用调用者信息
+	TimeKey          string   // 时间的键名，默认 "time"
+	LevelKey         string   // 级别的键名，默认 "level"
+	MessageKey       string   // 消息的键名，默认 "msg"
+	CallerKey        string   // 调用者的键名，默认 "caller"
+	FieldOrder       []string // 优先输出的字段，其余字段按名称排序
+	NestedAsJSON     bool     // map、slice、struct 类型的字段值输出为 JSON
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+
+	timeKey := keyOrDefault(f.TimeKey, logfmtTimeKeyDef)
+	levelKey := keyOrDefault(f.LevelKey, logfmtLevelKeyDef)
+	msgKey := keyOrDefault(f.MessageKey, logfmtMessageKeyDef)
+	callerKey := keyOrDefault(f.CallerKey, logfmtCallerKeyDef)
+
+	if !f.DisableTimestamp {
+		format := f.TimestampFormat
+		if format == "" {
+			format = time.RFC3339Nano
+		}
+		appendLogfmtPair(b, timeKey, logfmtValue(entry.Time.Format(format)))
+	}
+	if !f.DisableLevel {
+		appendLogfmtPair(b, levelKey, entry.Level.String())
+	}
+	appendLogfmtPair(b, msgKey, logfmtValue(entry.Message))
+	if !f.DisableCaller && entry.HasCaller() {
+		appendLogfmtPair(b, callerKey, logfmtValue(entry.Caller.File+":"+strconv.Itoa(entry.Caller.Line)))
+	}
+
+	reserved := map[string]bool{timeKey: true, levelKey: true, msgKey: true, callerKey: true}
+	for _, k := range orderedKeys(entry.Data, f.FieldOrder) {
+		key := logfmtKey(k)
+		if reserved[key] {
+			key = "fields." + key
+		}
+		appendLogfmtPair(b, key, fieldText(entry.Data[k], f.NestedAsJSON, true))
+	}
+
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// appendLogfmtPair 追加一个 key=value，value 需要已按需加引号
+func appendLogfmtPair(b *bytes.Buffer, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	b.WriteString(value)
+}
+
+// logfmtValue 对包含空白、引号、= 或控制字符的值加引号并转义
+func logfmtValue(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// logfmtKey 将键中 logfmt 不允许的字符替换为 _
+func logfmtKey(k string) string {
+	if k == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, k)
+}
+
+// keyOrDefault 返回 key，为空时返回默认值
+func keyOrDefault(key, def string) string {
+	if key == "" {
+		return def
+	}
+	return logfmtKey(key)
+}
@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestLogfmtFormatter 测试 logfmt 输出的转义、字段顺序和键名冲突
+func TestLogfmtFormatter(t *testing.T) {
+	f := &LogfmtFormatter{TimestampFormat: time.RFC3339}
+	data := logrus.Fields{
+		"user":    "li lei",
+		"count":   3,
+		"msg":     "dup",
+		"bad key": "x",
+		"path":    `C:\tmp`,
+		"nested":  map[string]int{"a": 1},
+	}
+	want := `time=2026-01-02T03:04:05Z level=info msg=hello bad_key=x count=3 fields.msg=dup nested=map[a:1] path="C:\\tmp" user="li lei"` + "\n"
+	for i := 0; i < 5; i++ {
+		if got := formatWithFields(t, f, data); got != want {
+			t.Fatalf("got  %q\nwant %q", got, want)
+		}
+	}
+
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry.Level = logrus.WarnLevel
+	entry.Message = "disk \"almost\" full\nretry"
+	entry.Data = logrus.Fields{"user": "bob", "nested": map[string]int{"a": 1}, "id": 7}
+	entry.Caller = &runtime.Frame{File: "/src/app/main.go", Line: 42}
+	entry.Logger.ReportCaller = true
+
+	f = &LogfmtFormatter{
+		DisableTimestamp: true,
+		TimeKey:          "ts",
+		LevelKey:         "lvl",
+		MessageKey:       "message",
+		FieldOrder:       []string{"user"},
+		NestedAsJSON:     true,
+	}
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `lvl=warning message="disk \"almost\" full\nretry" caller=/src/app/main.go:42 user=bob id=7 nested={"a":1}` + "\n"
+	if string(b) != want {
+		t.Errorf("got  %q\nwant %q", b, want)
+	}
+}
+
+// TestLogfmtSettings 测试通过 formatter_type: logfmt 选择格式器并设置键名
+func TestLogfmtSettings(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+formatter_type: logfmt
+time_key: ts
+level_key: severity
+message_key: message
+field_order: [request_id]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, ok := (&FormatterFactory{}).CreateFormatter(s).(*LogfmtFormatter)
+	if !ok {
+		t.Fatal("expected LogfmtFormatter")
+	}
+	out := formatWithFields(t, f, logrus.Fields{"a": 1, "request_id": "r-1"})
+	if !strings.HasPrefix(out, `ts="2026-01-02 03:04:05.000" severity=info message=hello request_id=r-1 a=1`) {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	// 键名同样作用于 json 格式器
+	s.FormatterType = FormatterTypeJSON
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(formatWithFields(t, (&FormatterFactory{}).CreateFormatter(s), nil)), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["message"] != "hello" || m["severity"] != "info" || m["ts"] == nil {
+		t.Errorf("unexpected json output %v", m)
+	}
+}
@@ -2,7 +2,7 @@ package logger
 
 import (
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -16,92 +16,378 @@ import (
 	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
 	"github.com/sirupsen/logrus"
 	easy "github.com/t-tomalak/logrus-easy-formatter"
-	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 )
 
-func GetLogger() (*logrus.Logger, error) {
-	var initErr error
-	loggerOnce.Do(func() {
-		settings := NewSettings()
-		loggerBase, initErr = NewLogHelperWithError(settings)
-	})
+// Logger 日志器实例，持有各自的写入器与轮转状态
+// 同一进程内可以创建多个 Logger，分别写入不同的日志文件，互不影响
+type Logger struct {
+	*logrus.Logger
+
+	mu                  sync.RWMutex
+	settings            *Settings
+	rotateLogsWriter    *rotatelogs.RotateLogs  // 日志轮转记录器
+	sizeWriter          *sizeRotatingWriter     // 大小轮转记录器（需要资源管理）
+	timeSizeWriter      *timeSizeRotatingWriter // 时间和大小组合轮转记录器
+	currentLogFileFPath string                  // 当前日志文件路径
+	moduleFilter        *moduleLevelFilter      // 按模块覆盖的日志级别，未配置时为 nil
+	asyncWriter         *asyncWriter            // 异步写入器，未开启异步模式时为 nil
+	sinkHook            *sinkHook               // 分发到文件之外的输出
+	closers             []io.Closer             // 需要按顺序关闭的写入器
+}
 
-	if initErr != nil {
-		return nil, fmt.Errorf("logger initialization failed: %w", initErr)
+// New 根据设置创建一个独立的日志器实例
+// 使用完毕后应调用 Close 释放文件句柄
+func New(settings *Settings) (*Logger, error) {
+	return newLogger(settings, true)
+}
+
+// newLogger 根据设置创建日志器，openSinks 为 false 时不创建文件之外的输出，由 Reload 另行重建
+func newLogger(settings *Settings, openSinks bool) (_ *Logger, err error) {
+	// 首先验证设置
+	if err = validateSettings(settings); err != nil {
+		return nil, fmt.Errorf("invalid settings: %w", err)
 	}
 
-	loggerMutex.RLock()
-	defer loggerMutex.RUnlock()
+	l := &Logger{settings: settings}
+	// 创建失败时关闭已创建的写入器和输出
+	defer func() {
+		if err != nil {
+			_ = closeWriters(l.closers)
+			if l.sinkHook != nil {
+				_ = l.sinkHook.close()
+			}
+		}
+	}()
+
+	// 使用格式器工厂创建格式器
+	factory := &FormatterFactory{}
+	formatter, err := factory.CreateFormatterWithError(settings)
+	if err != nil {
+		return nil, err
+	}
 
-	if loggerBase == nil {
-		return nil, errors.New("logger not initialized")
+	console := consoleWriter(settings)
+	consoleLevel := console != nil && settings.ConsoleLevel != nil
+	if len(settings.ModuleLevels) > 0 || len(settings.Routes) > 0 || consoleLevel {
+		l.moduleFilter = newModuleLevelFilter(settings.Level, settings.ModuleLevels)
+		for i := range settings.Routes {
+			if level := settings.Routes[i].mostVerbose(); level > l.moduleFilter.routeLevel {
+				l.moduleFilter.routeLevel = level
+			}
+		}
+		if consoleLevel && *settings.ConsoleLevel > l.moduleFilter.routeLevel {
+			l.moduleFilter.routeLevel = *settings.ConsoleLevel
+		}
+		formatter = &moduleLevelFormatter{Formatter: formatter, filter: l.moduleFilter}
+	}
+
+	l.Logger = &logrus.Logger{
+		Formatter:    formatter,
+		Hooks:        make(logrus.LevelHooks),
+		ReportCaller: !settings.DisableCaller,
+		ExitFunc:     l.exit,
+	}
+	l.AddHook(callerHook{})
+
+	// 文件之外的输出通过 Hook 分发，与文件输出使用相同的模块级别过滤，由 sinkHook 负责关闭
+	l.sinkHook = &sinkHook{filter: l.moduleFilter}
+	l.AddHook(l.sinkHook)
+	if openSinks {
+		if l.sinkHook.sinks, err = newSinks(settings); err != nil {
+			return nil, err
+		}
+	}
+
+	pathRoot := settings.logDir()
+	if _, err = os.Stat(pathRoot); os.IsNotExist(err) {
+		err = os.MkdirAll(pathRoot, 0750) // 使用更安全的权限：所有者读写执行，组和其他用户只读
+		if err != nil {
+			return nil, fmt.Errorf("create log dir failed: %w", err)
+		}
+	}
+
+	file, err := openRotatingFile(settings, pathRoot)
+	if err != nil {
+		return nil, err
+	}
+	l.closers = append(l.closers, file.closers...)
+	l.rotateLogsWriter = file.rotateLogs
+	l.sizeWriter = file.size
+	l.timeSizeWriter = file.timeSize
+	if l.sizeWriter != nil {
+		l.currentLogFileFPath = l.sizeWriter.Filename()
+	} else if l.rotateLogsWriter != nil {
+		// 使用 rotatelogs 提供的当前文件名
+		l.currentLogFileFPath = l.rotateLogsWriter.CurrentFileName()
+	}
+	fileWriter := file.Writer
+
+	// 按级别路由的文件与主文件使用相同的格式器，由 sinkHook 按各自的级别分发
+	routes, err := newRouteSinks(settings, pathRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		l.closers = append(l.closers, r)
 	}
-	return loggerBase, nil
+	l.sinkHook.routes = routes
+
+	// 控制台与文件的级别或格式器不同时单独写入，否则与文件共用格式化结果
+	out := fileWriter
+	if console != nil {
+		if settings.separateConsole() {
+			cs := newConsoleSink(console, settings)
+			l.closers = append(l.closers, cs)
+			l.sinkHook.routes = append(l.sinkHook.routes, cs)
+		} else {
+			out = io.MultiWriter(console, fileWriter)
+		}
+	}
+
+	l.SetLevel(settings.Level)
+
+	// 异步模式：写入放入队列，由后台协程写到实际输出，关闭时需要先于文件写入器排空
+	if settings.Async {
+		l.asyncWriter = newAsyncWriter(out, settings)
+		l.Formatter = &asyncLevelFormatter{Formatter: l.Formatter, writer: l.asyncWriter}
+		l.closers = append([]io.Closer{l.asyncWriter}, l.closers...)
+		out = l.asyncWriter
+	}
+	l.SetOutput(out)
+
+	// 记录清理错误，但不影响日志器的创建
+	if err := file.cleanup(); err != nil {
+		// 使用刚创建的日志器记录错误，避免循环依赖
+		l.Warnf("Failed to cleanup expired logs: %v", err)
+	}
+	for _, r := range routes {
+		if err := r.file.cleanup(); err != nil {
+			l.Warnf("Failed to cleanup expired route logs: %v", err)
+		}
+	}
+
+	return l, nil
 }
 
-// GetLoggerUnsafe 保持向后兼容的包装函数，忽略错误
-func GetLoggerUnsafe() *logrus.Logger {
-	logger, _ := GetLogger()
-	return logger
+// newFallbackLogger 创建仅输出到 stderr 的日志器，用于初始化失败时兜底
+func newFallbackLogger() *Logger {
+	return &Logger{Logger: logrus.New()}
 }
 
-func SetLoggerSettings(inSettings ...*Settings) {
-	loggerMutex.Lock()
-	defer loggerMutex.Unlock()
+// Close 关闭日志器持有的文件写入器，异步模式下先写完队列中的条目
+// 重复调用是安全的
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := closeWriters(l.closers)
+	l.closers = nil
+	if l.sinkHook != nil {
+		if sinkErr := l.sinkHook.close(); sinkErr != nil && err == nil {
+			err = sinkErr
+		}
+	}
+	l.sizeWriter = nil
+	l.timeSizeWriter = nil
+	l.rotateLogsWriter = nil
 
-	var settings *Settings
-	if len(inSettings) > 0 {
-		settings = inSettings[0]
-	} else {
-		settings = NewSettings()
+	// 清空路径
+	l.currentLogFileFPath = ""
+
+	return err
+}
+
+// Reload 使用新的设置原地更新日志器的格式器、级别和文件写入器，已添加的 Hook 保持不变
+// logrus 在其互斥锁内完成格式化与写入，SetOutput 返回后旧写入器上不会再有写入，
+// 因此可以安全关闭旧写入器而不丢失正在写入的日志。新设置无效时保持原有配置不变
+func (l *Logger) Reload(settings *Settings) error {
+	n, err := newLogger(settings, false)
+	if err != nil {
+		return err
 	}
+	return l.apply(n)
+}
 
-	if settings.LogRootFPath == "" {
-		settings.LogRootFPath = logRootFPathDef
+// apply 把 newLogger 按新设置创建的写入器换入日志器，并重建文件之外的输出
+// 重建输出失败时关闭 n 的写入器，日志器保持原有配置
+func (l *Logger) apply(n *Logger) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.sinkHook == nil {
+		l.sinkHook = &sinkHook{}
+		l.AddHook(l.sinkHook)
+	}
+	// 磁盘缓冲目录由日志目录和名称决定，新旧输出不能同时打开，reopen 先关闭旧输出再创建新输出
+	sinkCloseErr, err := l.sinkHook.reopen(n.settings, l.settings)
+	if err != nil {
+		_ = closeWriters(n.closers)
+		return err
 	}
 
-	if settings.LogNameBase == "" {
-		settings.LogNameBase = NameDef
+	oldClosers := l.closers
+
+	l.Logger.SetFormatter(n.Formatter)
+	l.Logger.SetLevel(n.Logger.GetLevel())
+	l.Logger.SetReportCaller(n.ReportCaller)
+	l.Logger.SetOutput(n.Out)
+	l.sinkHook.setRoutes(n.sinkHook.routes, n.sinkHook.filter)
+
+	l.settings = n.settings
+	l.moduleFilter = n.moduleFilter
+	l.asyncWriter = n.asyncWriter
+	l.closers = n.closers
+	l.sizeWriter = n.sizeWriter
+	l.timeSizeWriter = n.timeSizeWriter
+	l.rotateLogsWriter = n.rotateLogsWriter
+	l.currentLogFileFPath = n.currentLogFileFPath
+
+	if err := closeWriters(oldClosers); err != nil {
+		return err
 	}
+	return sinkCloseErr
+}
 
-	if settings.RotationTime <= 0 {
-		settings.RotationTime = time.Duration(24) * time.Hour // 默认每天轮转一次
+// Flush 等待异步队列中已有的条目全部写入，并立即发送批量输出中攒批的条目
+func (l *Logger) Flush(ctx context.Context) error {
+	l.mu.RLock()
+	w := l.asyncWriter
+	h := l.sinkHook
+	l.mu.RUnlock()
+
+	if w != nil {
+		if err := w.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	if h != nil {
+		return h.flush(ctx)
 	}
+	return nil
+}
 
-	if settings.MaxAgeDays > 0 {
-		settings.MaxAge = time.Duration(settings.MaxAgeDays*24) * time.Hour
+// AsyncStats 返回异步写入统计，未开启异步模式时返回零值
+func (l *Logger) AsyncStats() AsyncStats {
+	l.mu.RLock()
+	w := l.asyncWriter
+	l.mu.RUnlock()
+
+	if w == nil {
+		return AsyncStats{}
 	}
-	if settings.MaxAge <= 0 {
-		settings.MaxAge = time.Duration(7*24) * time.Hour
+	return w.stats()
+}
+
+// exit 作为 logrus 的 ExitFunc，退出前尽量写完异步队列
+func (l *Logger) exit(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), exitFlushTimeout)
+	_ = l.Flush(ctx)
+	cancel()
+	os.Exit(code)
+}
+
+// closeWriters 按顺序关闭写入器
+func closeWriters(closers []io.Closer) error {
+	var closeErrors []error
+
+	for _, c := range closers {
+		if err := c.Close(); err != nil {
+			closeErrors = append(closeErrors, fmt.Errorf("failed to close %T: %w", c, err))
+		}
 	}
 
-	// 关闭旧的资源（如果有的话）
-	if err := closeOldResources(); err != nil {
-		// 记录错误但不阻止设置继续进行
-		fmt.Fprintf(os.Stderr, "Warning: Failed to close old resources: %v\n", err)
+	if len(closeErrors) > 0 {
+		return fmt.Errorf("close errors: %v", closeErrors)
 	}
+	return nil
+}
 
-	var err error
-	loggerBase, err = NewLogHelperWithError(settings)
-	if err != nil {
-		// 为了向后兼容，在这里仍然打印错误并使用默认日志器
-		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
-		loggerBase = logrus.New()
+// LogLinkFileFPath 当前日志文件的完整路径
+func (l *Logger) LogLinkFileFPath() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.sizeWriter != nil {
+		return l.sizeWriter.Filename()
+	}
+	if l.timeSizeWriter != nil {
+		return l.timeSizeWriter.Filename()
 	}
+	return l.currentLogFileFPath
 }
 
-// SetLoggerSettingsWithError 设置日志配置，返回错误
-func SetLoggerSettingsWithError(inSettings ...*Settings) error {
-	loggerMutex.Lock()
-	defer loggerMutex.Unlock()
+// CurrentFileName 当前日志文件名
+func (l *Logger) CurrentFileName() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
 
-	var settings *Settings
-	if len(inSettings) > 0 {
-		settings = inSettings[0]
-	} else {
-		settings = NewSettings()
+	if l.rotateLogsWriter != nil {
+		return l.rotateLogsWriter.CurrentFileName()
+	}
+	if l.sizeWriter != nil {
+		return l.sizeWriter.Filename()
+	}
+	if l.timeSizeWriter != nil {
+		return l.timeSizeWriter.Filename()
+	}
+	return l.currentLogFileFPath
+}
+
+// SetLevel 原地设置日志级别
+// 配置了模块级别时，该级别作为未匹配任何模块时的级别，logrus 级别取其与各模块级别中最详细的一个
+func (l *Logger) SetLevel(level logrus.Level) {
+	l.mu.RLock()
+	filter := l.moduleFilter
+	l.mu.RUnlock()
+
+	if filter == nil {
+		l.Logger.SetLevel(level)
+		return
+	}
+	filter.setBase(level)
+	l.Logger.SetLevel(filter.mostVerbose())
+}
+
+// GetLevel 返回日志级别，配置了模块级别时返回未匹配任何模块时的级别
+func (l *Logger) GetLevel() logrus.Level {
+	l.mu.RLock()
+	filter := l.moduleFilter
+	l.mu.RUnlock()
+
+	if filter == nil {
+		return l.Logger.GetLevel()
+	}
+	return filter.baseLevel()
+}
+
+// Settings 返回创建该日志器时使用的设置
+func (l *Logger) Settings() *Settings {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.settings
+}
+
+func GetLogger() (*logrus.Logger, error) {
+	l, err := getLoggerInternalWithError()
+	if err != nil {
+		return nil, fmt.Errorf("logger initialization failed: %w", err)
 	}
+	return l.Logger, nil
+}
 
+// GetLoggerUnsafe 保持向后兼容的包装函数，忽略错误
+func GetLoggerUnsafe() *logrus.Logger {
+	logger, _ := GetLogger()
+	return logger
+}
+
+// Default 返回包级函数所使用的默认日志器实例，必要时自动初始化
+func Default() *Logger {
+	return getLoggerInternal()
+}
+
+// normalizeSettings 为未设置的字段填充默认值
+func normalizeSettings(settings *Settings) {
 	if settings.LogRootFPath == "" {
 		settings.LogRootFPath = logRootFPathDef
 	}
@@ -120,182 +406,164 @@ func SetLoggerSettingsWithError(inSettings ...*Settings) error {
 	if settings.MaxAge <= 0 {
 		settings.MaxAge = time.Duration(7*24) * time.Hour
 	}
-
-	// 关闭旧的资源（如果有的话）
-	if err := closeOldResources(); err != nil {
-		// 记录错误但不阻止设置继续进行
-		fmt.Fprintf(os.Stderr, "Warning: Failed to close old resources: %v\n", err)
-	}
-
-	var err error
-	loggerBase, err = NewLogHelperWithError(settings)
-	return err
 }
 
-func NewLogHelper(settings *Settings) *logrus.Logger {
-	logger, err := NewLogHelperWithError(settings)
-	if err != nil {
-		// 向后兼容：如果出错，panic
-		panic(err)
+func SetLoggerSettings(inSettings ...*Settings) {
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+
+	if err := setLoggerSettingsLocked(inSettings...); err != nil {
+		// 为了向后兼容，在这里仍然打印错误并使用默认日志器
+		fmt.Fprintf(os.Stderr, "Failed to create logger: %v\n", err)
+		defaultLogger = newFallbackLogger()
 	}
-	return logger
 }
 
-// NewLogHelperWithError 创建日志助手，返回错误
-func NewLogHelperWithError(settings *Settings) (*logrus.Logger, error) {
-	var err error
+// SetLoggerSettingsWithError 设置日志配置，返回错误
+func SetLoggerSettingsWithError(inSettings ...*Settings) error {
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
 
-	// 首先验证设置
-	if err = validateSettings(settings); err != nil {
-		return nil, fmt.Errorf("invalid settings: %w", err)
-	}
+	return setLoggerSettingsLocked(inSettings...)
+}
 
-	// 使用格式器工厂创建格式器
-	factory := &FormatterFactory{}
-	formatter := factory.CreateFormatter(settings)
+// ReloadLoggerSettings 使用新的设置原地更新默认日志器及所有命名日志器
+// 与 SetLoggerSettings 不同，已获取的 *Logger 和 *logrus.Logger 引用继续有效，且不会丢失正在写入的日志。
+// 先为所有日志器创建新的写入器，新设置无效或任一日志器创建失败时所有日志器保持原有配置并返回错误；
+// 之后个别日志器重建 Syslog、Network 等输出失败时，这些日志器保持原有配置，返回的错误中列出它们的名称
+func ReloadLoggerSettings(settings *Settings) error {
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+	namedLoggersMutex.Lock()
+	defer namedLoggersMutex.Unlock()
 
-	Logger := &logrus.Logger{
-		Formatter: formatter,
-	}
+	normalizeSettings(settings)
 
-	pathRoot := filepath.Join(settings.LogRootFPath, "Logs")
-	if settings.LogRootFPath != logRootFPathDef {
-		pathRoot = settings.LogRootFPath
+	type reloadTarget struct {
+		name string
+		l    *Logger // 需要更新的日志器
+		n    *Logger // 按新设置创建的写入器
 	}
-	if _, err = os.Stat(pathRoot); os.IsNotExist(err) {
-		err = os.MkdirAll(pathRoot, 0750) // 使用更安全的权限：所有者读写执行，组和其他用户只读
-		if err != nil {
-			return nil, fmt.Errorf("create log dir failed: %w", err)
+	var targets []reloadTarget
+	abort := func() {
+		for _, t := range targets {
+			_ = closeWriters(t.n.closers)
 		}
 	}
 
-	var fileWriter io.Writer
-	if settings.MaxSizeMB > 0 {
-		// 大小轮转模式
-		var logDir string
-		if settings.UseHierarchicalPath {
-			// 新格式：按年/月/日分层
-			now := time.Now()
-			yearDir := filepath.Join(pathRoot, now.Format("2006"))
-			monthDir := filepath.Join(yearDir, now.Format("01"))
-			dayDir := filepath.Join(monthDir, now.Format("02"))
-			logDir = dayDir
-		} else {
-			// 旧格式：扁平结构
-			logDir = pathRoot
-		}
-
-		if _, err = os.Stat(logDir); os.IsNotExist(err) {
-			err = os.MkdirAll(logDir, 0750) // 使用更安全的权限：所有者读写执行，组和其他用户只读
-			if err != nil {
-				return nil, fmt.Errorf("create log dir failed: %w", err)
-			}
-		}
-
-		currentLogFileFPath = filepath.Join(logDir, settings.LogNameBase+".log")
-		lumberjackWriter = &lumberjack.Logger{
-			Filename:  currentLogFileFPath,
-			MaxSize:   settings.MaxSizeMB,
-			MaxAge:    settings.MaxAgeDays,
-			LocalTime: true,
-			Compress:  false,
+	if defaultLogger != nil && defaultLogger.Settings() != nil {
+		n, err := newLogger(settings, false)
+		if err != nil {
+			return err
 		}
-		fileWriter = lumberjackWriter
-		rotateLogsWriter = nil
-	} else {
-		// 时间轮转模式
-		var logPattern string
-		if settings.UseHierarchicalPath {
-			// 新格式：按年/月/日分层
-			logPattern = filepath.Join(pathRoot, "%Y", "%m", "%d", settings.LogNameBase+"--%H%M--.log")
-		} else {
-			// 旧格式：扁平结构
-			logPattern = filepath.Join(pathRoot, settings.LogNameBase+"--%Y%m%d%H%M--.log")
+		targets = append(targets, reloadTarget{name: "default", l: defaultLogger, n: n})
+	}
+	for name, l := range namedLoggers {
+		n, err := newLogger(settings.named(name), false)
+		if err != nil {
+			abort()
+			return fmt.Errorf("reload logger %q failed: %w", name, err)
 		}
+		targets = append(targets, reloadTarget{name: name, l: l, n: n})
+	}
 
-		rotateLogsWriter, err = rotatelogs.New(
-			logPattern,
-			rotatelogs.WithMaxAge(settings.MaxAge),
-			rotatelogs.WithRotationTime(settings.RotationTime),
-		)
+	// 默认日志器尚未创建或是兜底日志器时，直接创建新的日志器
+	if defaultLogger == nil || defaultLogger.Settings() == nil {
+		l, err := New(settings)
 		if err != nil {
-			return nil, fmt.Errorf("create log file failed: %w", err)
+			abort()
+			return err
 		}
-		fileWriter = rotateLogsWriter
-		// 使用 rotatelogs 提供的当前文件名
-		currentLogFileFPath = rotateLogsWriter.CurrentFileName()
-		lumberjackWriter = nil  // 时间轮转模式下不使用 lumberjack
+		defaultLogger = l
 	}
 
-	Logger.SetLevel(settings.Level)
-	// 在Windows下，如果使用-H=windowsgui编译，os.Stderr将无效，所以需要特殊处理
-	if isWindowsGUI() {
-		Logger.SetOutput(fileWriter)
-	} else {
-		Logger.SetOutput(io.MultiWriter(os.Stderr, fileWriter))
+	var reloadErrors []error
+	for _, t := range targets {
+		if err := t.l.apply(t.n); err != nil {
+			reloadErrors = append(reloadErrors, fmt.Errorf("logger %q: %w", t.name, err))
+		}
 	}
-
-	// 记录清理错误，但不影响日志器的创建
-	if err := CleanupExpiredLogs(pathRoot, settings.MaxAgeDays); err != nil {
-		// 使用刚创建的日志器记录错误，避免循环依赖
-		Logger.Warnf("Failed to cleanup expired logs: %v", err)
+	if len(reloadErrors) > 0 {
+		return fmt.Errorf("reload loggers errors: %v", reloadErrors)
 	}
-
-	return Logger, nil
+	return nil
 }
 
-// closeOldResources 关闭旧的日志资源（需要在 loggerMutex 锁保护下调用）
-func closeOldResources() error {
-	var closeErrors []error
+// setLoggerSettingsLocked 替换默认日志器（需要在 loggerMutex 锁保护下调用）
+func setLoggerSettingsLocked(inSettings ...*Settings) error {
+	var settings *Settings
+	if len(inSettings) > 0 {
+		settings = inSettings[0]
+	} else {
+		settings = NewSettings()
+	}
+	normalizeSettings(settings)
 
-	// 关闭 lumberjack writer（如果存在）
-	if lumberjackWriter != nil {
-		if err := lumberjackWriter.Close(); err != nil {
-			closeErrors = append(closeErrors, fmt.Errorf("failed to close lumberjack writer: %w", err))
+	// 关闭旧的资源（如果有的话）
+	if defaultLogger != nil {
+		if err := defaultLogger.Close(); err != nil {
+			// 记录错误但不阻止设置继续进行
+			fmt.Fprintf(os.Stderr, "Warning: Failed to close old resources: %v\n", err)
 		}
-		lumberjackWriter = nil
+	}
+	// 命名日志器基于旧设置创建，关闭后在下次 Named 调用时按新设置重建
+	if err := closeNamedLoggers(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to close named loggers: %v\n", err)
 	}
 
-	// 关闭 rotatelogs writer
-	// rotatelogs.RotateLogs 没有 Close 方法，所以我们只需要将其设为 nil
-	rotateLogsWriter = nil
+	var err error
+	defaultLogger, err = New(settings)
+	return err
+}
 
-	// 清空路径
-	currentLogFileFPath = ""
+func NewLogHelper(settings *Settings) *logrus.Logger {
+	logger, err := NewLogHelperWithError(settings)
+	if err != nil {
+		// 向后兼容：如果出错，panic
+		panic(err)
+	}
+	return logger
+}
 
-	if len(closeErrors) > 0 {
-		return fmt.Errorf("close old resources errors: %v", closeErrors)
+// NewLogHelperWithError 创建日志助手，返回错误
+// 返回的 logrus.Logger 不暴露底层文件写入器，需要显式关闭时请使用 New
+func NewLogHelperWithError(settings *Settings) (*logrus.Logger, error) {
+	l, err := New(settings)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return l.Logger, nil
 }
 
-// LogLinkFileFPath returns the path of the current log file.
+// LogLinkFileFPath 当前日志文件的完整路径
 func LogLinkFileFPath() string {
 	loggerMutex.RLock()
 	defer loggerMutex.RUnlock()
-	return currentLogFileFPath
+	if defaultLogger == nil {
+		return ""
+	}
+	return defaultLogger.LogLinkFileFPath()
 }
 
-// Close 关闭日志器并释放所有资源
+// Close 关闭日志器并释放所有资源，包括所有通过 Named 创建的日志器
 // 应用程序退出前应该调用此函数以确保所有日志被正确写入
 func Close() error {
 	loggerMutex.Lock()
 	defer loggerMutex.Unlock()
 
-	// 防止重复关闭
-	if loggerBase == nil && lumberjackWriter == nil && rotateLogsWriter == nil {
-		return nil
-	}
-
 	var closeErrors []error
 
-	// 关闭所有资源
-	if err := closeOldResources(); err != nil {
-		closeErrors = append(closeErrors, err)
+	if defaultLogger != nil {
+		if err := defaultLogger.Close(); err != nil {
+			closeErrors = append(closeErrors, err)
+		}
+		// 清理 logger 实例
+		defaultLogger = nil
 	}
 
-	// 清理 logger 实例
-	loggerBase = nil
+	if err := closeNamedLoggers(); err != nil {
+		closeErrors = append(closeErrors, err)
+	}
 
 	if len(closeErrors) > 0 {
 		return fmt.Errorf("close errors: %v", closeErrors)
@@ -303,15 +571,19 @@ func Close() error {
 	return nil
 }
 
+// Flush 等待默认日志器异步队列中已有的条目全部写入，并立即发送批量输出中攒批的条目
+func Flush(ctx context.Context) error {
+	return getLoggerInternal().Flush(ctx)
+}
+
 // CurrentFileName 当前日志文件名
 func CurrentFileName() string {
 	loggerMutex.RLock()
 	defer loggerMutex.RUnlock()
-
-	if rotateLogsWriter != nil {
-		return rotateLogsWriter.CurrentFileName()
+	if defaultLogger == nil {
+		return ""
 	}
-	return currentLogFileFPath
+	return defaultLogger.CurrentFileName()
 }
 
 const (
@@ -319,11 +591,19 @@ const (
 	logRootFPathDef     = "."
 	outputFormat        = "%time% - [%lvl%]: %msg%\n"
 	outputFormatOnlyMsg = "%msg%\n"
+	exitFlushTimeout    = 5 * time.Second // Fatal 退出前等待异步队列写完的最长时间
+	// 轮转策略常量
+	RotationPolicyDefault     = ""              // MaxSizeMB > 0 时按大小轮转，否则按时间轮转
+	RotationPolicySizeAndTime = "size_and_time" // RotationTime 到期或超过 MaxSizeMB 时轮转
 	// 格式器类型常量
 	FormatterTypeWithField = "withField"
 	FormatterTypeEasy      = "easy"
 	FormatterTypeJSON      = "json"
 	FormatterTypeText      = "text"
+	FormatterTypeLogfmt    = "logfmt"
+	FormatterTypeECS       = "ecs"
+	FormatterTypeGCP       = "gcp"
+	FormatterTypeTemplate  = "template"
 )
 
 type Settings struct {
@@ -335,17 +615,79 @@ type Settings struct {
 	MaxAge              time.Duration // 日志最大保存时间
 	MaxAgeDays          int
 	MaxSizeMB           int
+	MaxBackups          int  // 最多保留的轮转文件数量，0 表示不限制
+	MaxTotalSizeMB      int  // 日志文件总大小上限(MB)，超出时删除最旧的文件，0 表示不限制
 	UseHierarchicalPath bool // 是否使用分层路径（YYYY/MM/DD）
+	// 轮转策略：""（默认，MaxSizeMB > 0 时按大小轮转，否则按时间轮转）, "size_and_time"（任一条件满足即轮转）
+	RotationPolicy string
 
 	// 新增的格式器配置字段
-	FormatterType    string           // 格式器类型："withField", "easy", "json", "text"
+	FormatterType    string           // 格式器类型："withField", "easy", "json", "text", "logfmt", "ecs", "gcp", "template"
 	TimestampFormat  string           // 时间戳格式（默认 "2006-01-02 15:04:05.000"）
 	CustomFormatter  logrus.Formatter // 用户自定义格式器
 	DisableTimestamp bool             // 是否禁用时间戳
 	DisableLevel     bool             // 是否禁用日志级别
 	DisableCaller    bool             // 是否禁用调用者信息
 	FullTimestamp    bool             // 是否显示完整时间戳
-	LogFormat        string           // 自定义日志格式（用于 easy-formatter）
+	LogFormat        string           // 自定义日志格式（用于 easy-formatter），FormatterType 为 template 且未设置 TemplateFormat 时作为模板
+	TemplateFormat   string           // template 格式器使用的 text/template 模板，为空时见 LogFormat，都为空时输出与 withField 格式器相同
+
+	// withField 格式器的字段输出
+	FieldOrder         []string // 优先输出的字段，其余字段按名称排序
+	NestedFieldsAsJSON bool     // map、slice、struct 类型的字段值输出为 JSON
+	DisableFieldQuote  bool     // 不对包含空白、引号、= 或控制字符的字段值加引号转义
+
+	// logfmt 和 json 格式器中时间、级别、消息的键名，为空时使用各格式器的默认键名
+	TimeKey    string
+	LevelKey   string
+	MessageKey string
+
+	// gcp 格式器输出 trace 时使用的项目 ID，格式为 projects/<GCPProjectID>/traces/<trace ID>
+	GCPProjectID string
+
+	// 命名日志器配置
+	Loggers map[string]*LoggerOverride // 按名称覆盖的配置，见 Named
+
+	// 按模块覆盖的日志级别，键为 module 字段的值，或开启调用者信息时调用者的包路径
+	// 条目按最具体（最长）的匹配规则过滤，未匹配时使用 Level
+	ModuleLevels map[string]logrus.Level
+
+	// 异步写入配置
+	Async          bool         // 是否异步写入（由后台协程写文件，不阻塞调用方）
+	AsyncQueueSize int          // 异步队列长度（默认 1024）
+	AsyncOverflow  string       // 队列满时的策略："block", "drop_newest", "drop_oldest", "drop_below_level"
+	AsyncDropLevel logrus.Level // drop_below_level 策略下，队列满时丢弃比该级别更详细的条目
+
+	Compress string // 轮转后日志文件的压缩方式：""（不压缩）, "gzip", "zstd"
+
+	// 文件之外的输出，为 nil 时不启用
+	Syslog   *SyslogSettings   // 发送到 syslog
+	Journald *JournaldSettings // 通过原生协议发送到 systemd-journald
+	Network  *NetworkSettings  // 发送到 TCP/UDP 端点
+	HTTP     *HTTPSettings     // 以 NDJSON 批量 POST 到 HTTP 收集端
+	Loki     *LokiSettings     // 推送到 Grafana Loki
+	OTLP     *OTLPSettings     // 通过 OTLP/HTTP 导出到 OpenTelemetry 收集端
+	GELF     *GELFSettings     // 以 GELF 格式发送到 Graylog
+	Forward  *ForwardSettings  // 以 forward 协议发送到 Fluentd / Fluent Bit
+
+	// 按级别把条目另外写入单独的日志文件，如把 Warn 及以上写入 <LogNameBase>.error
+	Routes []LevelRoute
+
+	// 控制台输出配置，Windows GUI 模式下总是不输出到控制台
+	DisableConsole       bool          // 是否禁用控制台输出
+	ConsoleOutput        string        // 控制台输出目标："stderr"（默认）, "stdout"
+	ConsoleLevel         *logrus.Level // 控制台的日志级别，为 nil 时与 Level 相同
+	ConsoleFormatterType string        // 控制台的格式器类型，为空时与 FormatterType 相同
+	ConsoleColors        bool          // 控制台使用 text 或 template 格式器时输出颜色
+}
+
+// logDir 返回日志文件的根目录
+// 使用默认根目录时日志写入其下的 Logs 子目录，否则直接使用配置的目录
+func (s *Settings) logDir() string {
+	if s.LogRootFPath != logRootFPathDef {
+		return s.LogRootFPath
+	}
+	return filepath.Join(s.LogRootFPath, "Logs")
 }
 
 // NewSettings 创建一个新的日志设置
@@ -359,7 +701,10 @@ func NewSettings() *Settings {
 		MaxAge:              time.Duration(7*24) * time.Hour,
 		MaxAgeDays:          7,
 		MaxSizeMB:           0,
+		MaxBackups:          0, // 默认只按天数清理
+		MaxTotalSizeMB:      0,
 		UseHierarchicalPath: false, // 默认使用旧格式，保持向后兼容
+		RotationPolicy:      RotationPolicyDefault,
 
 		// 新增字段的默认值
 		FormatterType:    FormatterTypeWithField, // 默认使用 withField 格式器
@@ -370,30 +715,41 @@ func NewSettings() *Settings {
 		DisableCaller:    true, // 默认不显示调用者信息，保持简洁
 		FullTimestamp:    false,
 		LogFormat:        "",
+		TemplateFormat:   "",
+
+		// 异步写入默认关闭
+		Async:          false,
+		AsyncQueueSize: asyncQueueSizeDef,
+		AsyncOverflow:  OverflowBlock,
+		AsyncDropLevel: logrus.WarnLevel, // drop_below_level 策略下保留 Warn 及以上级别
+
+		Compress: CompressNone, // 默认不压缩
+
+		ConsoleOutput: ConsoleOutputStderr, // 默认输出到标准错误，保持向后兼容
 	}
 }
 
 var (
-	loggerBase          *logrus.Logger         // 日志基础记录器
-	rotateLogsWriter    *rotatelogs.RotateLogs // 日志轮转记录器
-	lumberjackWriter    *lumberjack.Logger     // 大小轮转记录器（需要资源管理）
-	currentLogFileFPath string                 // 当前日志文件路径
-	loggerMutex         sync.RWMutex           // 保护全局变量的互斥锁
-	loggerOnce          sync.Once              // 确保只初始化一次
+	defaultLogger *Logger      // 默认日志器实例，包级函数均委托给它
+	loggerMutex   sync.RWMutex // 保护全局变量的互斥锁
 
 	// Windows GUI 检测缓存
-	isGUICached     bool
+	isGUICached      bool
 	isGUICachedValue bool
 	isGUICachedOnce  sync.Once
 )
 
 // WithFieldFormatter 自定义日志格式器，支持结构化字段输出
-// 输出格式：2025-12-18 18:32:07.379 - [INFO]: 【实时通知】事件广播成功 operation=(a+b)-c result=123.45
+// 输出格式：2025-12-18 18:32:07.379 - [INFO]: 【实时通知】事件广播成功 operation=(a+b)-c result=123.45 user="li lei"
+// 字段按 FieldOrder 和字段名排序，每行的顺序固定
 type WithFieldFormatter struct {
-	TimestampFormat  string // 时间戳格式
-	DisableTimestamp bool   // 是否禁用时间戳
-	DisableLevel     bool   // 是否禁用日志级别
-	DisableCaller    bool   // 是否禁用调用者信息
+	TimestampFormat  string   // 时间戳格式
+	DisableTimestamp bool     // 是否禁用时间戳
+	DisableLevel     bool     // 是否禁用日志级别
+	DisableCaller    bool     // 是否禁用调用者信息
+	FieldOrder       []string // 优先输出的字段，其余字段按名称排序
+	NestedAsJSON     bool     // map、slice、struct 类型的字段值输出为 JSON
+	DisableQuote     bool     // 不对包含空白、引号、= 或控制字符的字段值加引号转义
 }
 
 // Format 实现 logrus.Formatter 接口
@@ -429,15 +785,11 @@ func (f *WithFieldFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	b.WriteString(entry.Message)
 
 	// 如果有字段，将它们附加到消息后面
-	if len(entry.Data) > 0 {
-		var fields []string
-		for k, v := range entry.Data {
-			fields = append(fields, fmt.Sprintf("%s=%v", k, v))
-		}
-		if len(fields) > 0 {
-			b.WriteString(" ")
-			b.WriteString(strings.Join(fields, " "))
-		}
+	for _, k := range orderedKeys(entry.Data, f.FieldOrder) {
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(fieldText(entry.Data[k], f.NestedAsJSON, !f.DisableQuote))
 	}
 
 	b.WriteString("\n")
@@ -447,11 +799,23 @@ func (f *WithFieldFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 // FormatterFactory 格式器工厂
 type FormatterFactory struct{}
 
-// CreateFormatter 根据设置创建相应的格式器
+// CreateFormatter 根据设置创建相应的格式器，模板无法解析时输出错误并使用 withField 格式器
 func (f *FormatterFactory) CreateFormatter(settings *Settings) logrus.Formatter {
+	formatter, err := f.CreateFormatterWithError(settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create formatter: %v\n", err)
+		fs := *settings
+		fs.FormatterType = FormatterTypeWithField
+		formatter, _ = f.CreateFormatterWithError(&fs)
+	}
+	return formatter
+}
+
+// CreateFormatterWithError 根据设置创建相应的格式器，模板无法解析时返回错误
+func (f *FormatterFactory) CreateFormatterWithError(settings *Settings) (logrus.Formatter, error) {
 	// 优先使用自定义格式器
 	if settings.CustomFormatter != nil {
-		return settings.CustomFormatter
+		return settings.CustomFormatter, nil
 	}
 
 	// 处理向后兼容：如果设置了 OnlyMsg，则使用 easy-formatter
@@ -471,14 +835,37 @@ func (f *FormatterFactory) CreateFormatter(settings *Settings) logrus.Formatter
 		return &logrus.JSONFormatter{
 			TimestampFormat:  settings.TimestampFormat,
 			DisableTimestamp: settings.DisableTimestamp,
-		}
+			FieldMap:         settings.fieldMap(),
+		}, nil
+	case FormatterTypeECS:
+		return &ECSFormatter{
+			ServiceName:   settings.LogNameBase,
+			DisableCaller: settings.DisableCaller,
+		}, nil
+	case FormatterTypeGCP:
+		return &GCPFormatter{
+			ProjectID:     settings.GCPProjectID,
+			DisableCaller: settings.DisableCaller,
+		}, nil
+	case FormatterTypeLogfmt:
+		return &LogfmtFormatter{
+			TimestampFormat:  settings.TimestampFormat,
+			DisableTimestamp: settings.DisableTimestamp,
+			DisableLevel:     settings.DisableLevel,
+			DisableCaller:    settings.DisableCaller,
+			TimeKey:          settings.TimeKey,
+			LevelKey:         settings.LevelKey,
+			MessageKey:       settings.MessageKey,
+			FieldOrder:       settings.FieldOrder,
+			NestedAsJSON:     settings.NestedFieldsAsJSON,
+		}, nil
 	case FormatterTypeText:
 		return &logrus.TextFormatter{
 			TimestampFormat:  settings.TimestampFormat,
 			DisableTimestamp: settings.DisableTimestamp,
 			DisableColors:    true,
 			FullTimestamp:    settings.FullTimestamp,
-		}
+		}, nil
 	case FormatterTypeEasy:
 		// 向后兼容 OnlyMsg
 		logFormat := settings.LogFormat
@@ -492,7 +879,16 @@ func (f *FormatterFactory) CreateFormatter(settings *Settings) logrus.Formatter
 		return &easy.Formatter{
 			TimestampFormat: settings.TimestampFormat,
 			LogFormat:       logFormat,
+		}, nil
+	case FormatterTypeTemplate:
+		text, field := settings.templateFormat()
+		tf, err := NewTemplateFormatter(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", field, err)
 		}
+		tf.TimestampFormat = settings.TimestampFormat
+		tf.NestedAsJSON = settings.NestedFieldsAsJSON
+		return tf, nil
 	case FormatterTypeWithField:
 		fallthrough
 	default:
@@ -502,15 +898,42 @@ func (f *FormatterFactory) CreateFormatter(settings *Settings) logrus.Formatter
 			DisableTimestamp: settings.DisableTimestamp,
 			DisableLevel:     settings.DisableLevel,
 			DisableCaller:    settings.DisableCaller,
-		}
+			FieldOrder:       settings.FieldOrder,
+			NestedAsJSON:     settings.NestedFieldsAsJSON,
+			DisableQuote:     settings.DisableFieldQuote,
+		}, nil
+	}
+}
+
+// templateFormat 返回模板格式器使用的模板及其所取自的设置项名称
+// 未设置 TemplateFormat 且文件使用 template 格式器时使用 LogFormat，否则 LogFormat 属于 easy 格式器
+func (s *Settings) templateFormat() (text, field string) {
+	if s.TemplateFormat == "" && s.FormatterType == FormatterTypeTemplate && !s.OnlyMsg {
+		return s.LogFormat, "LogFormat"
+	}
+	return s.TemplateFormat, "TemplateFormat"
+}
+
+// fieldMap 返回 json 格式器使用的键名映射，未设置键名时返回 nil
+func (s *Settings) fieldMap() logrus.FieldMap {
+	if s.TimeKey == "" && s.LevelKey == "" && s.MessageKey == "" {
+		return nil
+	}
+	m := logrus.FieldMap{}
+	if s.TimeKey != "" {
+		m[logrus.FieldKeyTime] = s.TimeKey
+	}
+	if s.LevelKey != "" {
+		m[logrus.FieldKeyLevel] = s.LevelKey
 	}
+	if s.MessageKey != "" {
+		m[logrus.FieldKeyMsg] = s.MessageKey
+	}
+	return m
 }
 
 // SetCustomFormatter 设置用户自定义格式器
 func SetCustomFormatter(formatter logrus.Formatter) {
-	if loggerBase == nil {
-		SetLoggerSettings(NewSettings())
-	}
 	settings := NewSettings()
 	settings.CustomFormatter = formatter
 	SetLoggerSettings(settings)
@@ -543,7 +966,7 @@ func checkWindowsGUIInternal() bool {
 
 	// 更准确的设备类型检查
 	mode := fileInfo.Mode()
-	return (mode & os.ModeDevice) != 0 && (mode & os.ModeCharDevice) == 0
+	return (mode&os.ModeDevice) != 0 && (mode&os.ModeCharDevice) == 0
 }
 
 // validateSettings 验证日志设置的合理性
@@ -573,11 +996,115 @@ func validateSettings(settings *Settings) error {
 		return fmt.Errorf("MaxAgeDays too large (max: 365 days)")
 	}
 
+	// 验证保留策略
+	if settings.MaxBackups < 0 {
+		return fmt.Errorf("MaxBackups cannot be negative")
+	}
+	if settings.MaxTotalSizeMB < 0 {
+		return fmt.Errorf("MaxTotalSizeMB cannot be negative")
+	}
+
 	// 验证 RotationTime
 	if settings.RotationTime < time.Minute {
 		return fmt.Errorf("RotationTime too small (min: 1 minute)")
 	}
 
+	// 验证轮转策略
+	switch settings.RotationPolicy {
+	case RotationPolicyDefault, RotationPolicySizeAndTime:
+	default:
+		return fmt.Errorf("unknown RotationPolicy: %s", settings.RotationPolicy)
+	}
+
+	// 验证异步写入配置
+	if settings.AsyncQueueSize < 0 {
+		return fmt.Errorf("AsyncQueueSize cannot be negative")
+	}
+	switch settings.AsyncOverflow {
+	case "", OverflowBlock, OverflowDropNewest, OverflowDropOldest, OverflowDropBelowLevel:
+	default:
+		return fmt.Errorf("unknown AsyncOverflow: %s", settings.AsyncOverflow)
+	}
+
+	// 验证模板格式器的模板，输出也可以单独使用 template 格式器，因此只要设置了模板就校验
+	if text, field := settings.templateFormat(); text != "" {
+		if _, err := NewTemplateFormatter(text); err != nil {
+			return fmt.Errorf("invalid %s: %w", field, err)
+		}
+	}
+
+	// 验证控制台输出配置
+	switch settings.ConsoleOutput {
+	case "", ConsoleOutputStderr, ConsoleOutputStdout:
+	default:
+		return fmt.Errorf("unknown ConsoleOutput: %s", settings.ConsoleOutput)
+	}
+	if settings.ConsoleLevel != nil && *settings.ConsoleLevel > logrus.TraceLevel {
+		return fmt.Errorf("invalid ConsoleLevel: %d", *settings.ConsoleLevel)
+	}
+
+	// 验证压缩方式
+	switch settings.Compress {
+	case CompressNone, CompressGzip, CompressZstd:
+	default:
+		return fmt.Errorf("unknown Compress: %s", settings.Compress)
+	}
+
+	// 验证输出配置
+	if settings.Syslog != nil {
+		if err := settings.Syslog.validate(); err != nil {
+			return fmt.Errorf("invalid Syslog: %w", err)
+		}
+	}
+
+	if settings.Network != nil {
+		if err := settings.Network.validate(); err != nil {
+			return fmt.Errorf("invalid Network: %w", err)
+		}
+	}
+
+	if settings.HTTP != nil {
+		if err := settings.HTTP.validate(); err != nil {
+			return fmt.Errorf("invalid HTTP: %w", err)
+		}
+	}
+
+	if settings.Loki != nil {
+		if err := settings.Loki.validate(); err != nil {
+			return fmt.Errorf("invalid Loki: %w", err)
+		}
+	}
+
+	if settings.OTLP != nil {
+		if err := settings.OTLP.validate(); err != nil {
+			return fmt.Errorf("invalid OTLP: %w", err)
+		}
+	}
+
+	if settings.GELF != nil {
+		if err := settings.GELF.validate(); err != nil {
+			return fmt.Errorf("invalid GELF: %w", err)
+		}
+	}
+
+	if settings.Forward != nil {
+		if err := settings.Forward.validate(); err != nil {
+			return fmt.Errorf("invalid Forward: %w", err)
+		}
+	}
+
+	names := make(map[string]bool, len(settings.Routes))
+	for i := range settings.Routes {
+		r := &settings.Routes[i]
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("invalid Routes: %w", err)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("invalid Routes: duplicate route name %q", r.Name)
+		}
+		names[r.Name] = true
+	}
+
 	// 验证日志名称
 	if settings.LogNameBase == "" {
 		return fmt.Errorf("LogNameBase cannot be empty")
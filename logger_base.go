@@ -3,25 +3,29 @@ package logger
 import (
 	"fmt"
 	"os"
+
 	"github.com/sirupsen/logrus"
 )
 
 // getLoggerInternal 获取当前日志器，内部使用，避免自动初始化
 // 为保持向后兼容性，此函数忽略错误，在初始化失败时会创建默认日志器
-func getLoggerInternal() *logrus.Logger {
+func getLoggerInternal() *Logger {
 	logger, err := getLoggerInternalWithError()
 	if err != nil {
 		// 如果初始化失败，创建一个默认的日志器并打印错误到 stderr
 		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
-		logger = logrus.New()
+		logger = newFallbackLogger()
 	}
 	return logger
 }
 
 // getLoggerInternalWithError 获取当前日志器，返回错误信息
-func getLoggerInternalWithError() (*logrus.Logger, error) {
-	// 快速路径：无锁读取
-	if logger := loggerBase; logger != nil {
+func getLoggerInternalWithError() (*Logger, error) {
+	// 快速路径：读锁
+	loggerMutex.RLock()
+	logger := defaultLogger
+	loggerMutex.RUnlock()
+	if logger != nil {
 		return logger, nil
 	}
 
@@ -30,16 +34,16 @@ func getLoggerInternalWithError() (*logrus.Logger, error) {
 	defer loggerMutex.Unlock()
 
 	// 双重检查
-	if loggerBase == nil {
+	if defaultLogger == nil {
 		settings := NewSettings()
-		logger, err := NewLogHelperWithError(settings)
+		logger, err := New(settings)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize logger: %w", err)
 		}
-		loggerBase = logger
+		defaultLogger = logger
 	}
 
-	return loggerBase, nil
+	return defaultLogger, nil
 }
 
 func Debugf(format string, args ...interface{}) {
@@ -3,7 +3,6 @@ package logger
 import (
 	"bytes"
 	"os"
-	"sync"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -22,9 +21,7 @@ func TestLoggerBaseFunctions(t *testing.T) {
 
 	// 使用锁保护设置全局变量
 	loggerMutex.Lock()
-	loggerMutex.Lock()
-	loggerBase = testLogger
-	loggerMutex.Unlock()
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	// 测试所有格式化函数
@@ -59,11 +56,11 @@ func TestLoggerBaseFunctions(t *testing.T) {
 			}
 
 			// 重置 buffer
-			loggerBase.Out = &bytes.Buffer{}
+			defaultLogger.Out = &bytes.Buffer{}
 			tc.function(tc.message)
 
 			// 验证输出
-			output := loggerBase.Out.(*bytes.Buffer).String()
+			output := defaultLogger.Out.(*bytes.Buffer).String()
 			if len(output) > 0 {
 				t.Logf("%s output length: %d", tc.name, len(output))
 			} else {
@@ -84,7 +81,7 @@ func TestLoggerBaseFormattedFunctions(t *testing.T) {
 	testLogger.Out = &bytes.Buffer{}       // 捕获输出
 	testLogger.SetLevel(logrus.DebugLevel) // 设置为 Debug 级别以确保所有日志都输出
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	// 测试所有格式化函数
@@ -120,11 +117,11 @@ func TestLoggerBaseFormattedFunctions(t *testing.T) {
 			}
 
 			// 重置 buffer
-			loggerBase.Out = &bytes.Buffer{}
+			defaultLogger.Out = &bytes.Buffer{}
 			tc.function(tc.format, tc.args...)
 
 			// 记录输出长度
-			output := loggerBase.Out.(*bytes.Buffer).String()
+			output := defaultLogger.Out.(*bytes.Buffer).String()
 			t.Logf("%s output length: %d", tc.name, len(output))
 			// 不强制要求输出，因为某些级别的日志可能被过滤或会终止程序
 		})
@@ -142,7 +139,7 @@ func TestLoggerBaseLnFunctions(t *testing.T) {
 	testLogger.Out = &bytes.Buffer{}       // 捕获输出
 	testLogger.SetLevel(logrus.DebugLevel) // 设置为 Debug 级别以确保所有日志都输出
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	// 测试所有带 ln 的函数
@@ -177,11 +174,11 @@ func TestLoggerBaseLnFunctions(t *testing.T) {
 			}
 
 			// 重置 buffer
-			loggerBase.Out = &bytes.Buffer{}
+			defaultLogger.Out = &bytes.Buffer{}
 			tc.function(tc.args...)
 
 			// 记录输出长度
-			output := loggerBase.Out.(*bytes.Buffer).String()
+			output := defaultLogger.Out.(*bytes.Buffer).String()
 			t.Logf("%s output length: %d", tc.name, len(output))
 			// 不强制要求输出，因为某些级别的日志可能被过滤或会终止程序
 		})
@@ -199,7 +196,7 @@ func TestWithFieldFunction(t *testing.T) {
 	testLogger.Out = &bytes.Buffer{}       // 捕获输出
 	testLogger.SetLevel(logrus.DebugLevel) // 设置为 Debug 级别以确保所有日志都输出
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	// 测试 WithField
@@ -218,7 +215,7 @@ func TestWithFieldFunction(t *testing.T) {
 	entry.Info("Test message with field")
 
 	// 验证输出包含字段信息
-	output := loggerBase.Out.(*bytes.Buffer).String()
+	output := defaultLogger.Out.(*bytes.Buffer).String()
 	if !contains(output, "key=value") {
 		t.Error("Output should contain field information")
 	}
@@ -235,7 +232,7 @@ func TestWithFieldsFunction(t *testing.T) {
 	testLogger.Out = &bytes.Buffer{}       // 捕获输出
 	testLogger.SetLevel(logrus.DebugLevel) // 设置为 Debug 级别以确保所有日志都输出
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	// 测试 WithFields
@@ -261,7 +258,7 @@ func TestWithFieldsFunction(t *testing.T) {
 	entry.Info("Test message with multiple fields")
 
 	// 验证输出包含所有字段信息
-	output := loggerBase.Out.(*bytes.Buffer).String()
+	output := defaultLogger.Out.(*bytes.Buffer).String()
 	for key := range fields {
 		if !contains(output, key+"=") {
 			t.Errorf("Output should contain field %s", key)
@@ -311,20 +308,14 @@ func TestSetLoggerNameFunction(t *testing.T) {
 func TestLoggerBaseNilHandling(t *testing.T) {
 	// 保存原始状态
 	backup := backupState()
-	// 需要重置 loggerOnce 以确保每次测试都能正确初始化
-	loggerOnce = sync.Once{} // 直接重新初始化，不复制锁
-	defer func() {
-		backup.restoreState()
-		// 恢复 loggerOnce 以便后续测试正常工作
-		loggerOnce = sync.Once{}
-	}()
+	defer backup.restoreState()
 
 	// 将日志器设置为 nil
 	loggerMutex.Lock()
-	loggerBase = nil
+	defaultLogger = nil
 	loggerMutex.Unlock()
 
-	// 测试所有函数在 loggerBase 为 nil 时的行为
+	// 测试所有函数在 defaultLogger 为 nil 时的行为
 	testCases := []struct {
 		name     string
 		function func()
@@ -356,15 +347,15 @@ func TestLoggerBaseNilHandling(t *testing.T) {
 			// 所有函数都应该能正常工作（自动初始化日志器）
 			defer func() {
 				if r := recover(); r != nil {
-					t.Errorf("%s panicked when loggerBase is nil: %v", tc.name, r)
+					t.Errorf("%s panicked when defaultLogger is nil: %v", tc.name, r)
 				}
 			}()
 
 			tc.function()
 
 			// 验证日志器已被初始化
-			if loggerBase == nil {
-				t.Errorf("%s did not initialize loggerBase", tc.name)
+			if defaultLogger == nil {
+				t.Errorf("%s did not initialize defaultLogger", tc.name)
 			}
 		})
 	}
@@ -390,14 +381,15 @@ func TestLoggerBaseLevelFiltering(t *testing.T) {
 	settings.Level = logrus.WarnLevel
 
 	// 直接创建日志器而不使用 SetLoggerSettings，避免被覆盖
-	testLogger, err := NewLogHelperWithError(settings)
+	testLogger, err := New(settings)
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer testLogger.Close()
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = testLogger
 	loggerMutex.Unlock()
-	loggerBase.Out = &bytes.Buffer{} // 捕获输出
+	defaultLogger.Out = &bytes.Buffer{} // 捕获输出
 
 	// 写入不同级别的日志
 	Debug("Debug message - should not appear")
@@ -406,7 +398,7 @@ func TestLoggerBaseLevelFiltering(t *testing.T) {
 	Error("Error message - should appear")
 
 	// 验证输出
-	output := loggerBase.Out.(*bytes.Buffer).String()
+	output := defaultLogger.Out.(*bytes.Buffer).String()
 	if contains(output, "Debug message") {
 		t.Error("Debug message should not appear when level is Warn")
 	}
@@ -436,7 +428,7 @@ func TestLoggerBaseConcurrentAccess(t *testing.T) {
 	testLogger.Out = &bytes.Buffer{}
 	testLogger.SetLevel(logrus.DebugLevel)
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	const numGoroutines = 20
@@ -487,7 +479,7 @@ func TestLoggerBaseComplexMessage(t *testing.T) {
 	testLogger := logrus.New()
 	testLogger.Out = &bytes.Buffer{}
 	loggerMutex.Lock()
-	loggerBase = testLogger
+	defaultLogger = &Logger{Logger: testLogger}
 	loggerMutex.Unlock()
 
 	// 测试各种复杂消息
@@ -540,7 +532,7 @@ func TestLoggerBaseComplexMessage(t *testing.T) {
 			}()
 
 			// 重置 buffer
-			loggerBase.Out = &bytes.Buffer{}
+			defaultLogger.Out = &bytes.Buffer{}
 			tc.function()
 
 			// 验证没有崩溃
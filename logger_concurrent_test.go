@@ -268,7 +268,7 @@ func TestConcurrentHierarchicalPath(t *testing.T) {
 	t.Log("Concurrent hierarchical path test completed successfully")
 }
 
-// TestConcurrentLoggerBaseAccess 测试并发访问 loggerBase
+// TestConcurrentLoggerBaseAccess 测试并发访问 defaultLogger
 func TestConcurrentLoggerBaseAccess(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping concurrent test in short mode")
@@ -444,7 +444,7 @@ func TestConcurrentLevelChanges(t *testing.T) {
 				// 写入不同级别的日志
 				logger, err := GetLogger()
 				if err != nil {
-					t.Errorf("Goroutine %d: GetLogger failed: %v", i, err)
+					t.Errorf("Goroutine %d: GetLogger failed: %v", id, err)
 					return
 				}
 				logger.Trace("Trace message")
@@ -497,7 +497,7 @@ func TestConcurrentGetLogger(t *testing.T) {
 	wg.Wait()
 
 	// 验证日志器已初始化
-	if loggerBase == nil {
+	if defaultLogger == nil {
 		t.Error("Logger should be initialized after concurrent GetLogger calls")
 	}
 
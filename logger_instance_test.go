@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNewIndependentInstances 测试多个实例分别写入各自的日志文件
+func TestNewIndependentInstances(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-instance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	newInstance := func(name string) *Logger {
+		settings := NewSettings()
+		settings.LogRootFPath = root
+		settings.LogNameBase = name
+		settings.MaxSizeMB = 1
+		l, err := New(settings)
+		if err != nil {
+			t.Fatalf("New(%s) failed: %v", name, err)
+		}
+		return l
+	}
+
+	auth := newInstance("auth")
+	billing := newInstance("billing")
+
+	if auth.CurrentFileName() == billing.CurrentFileName() {
+		t.Fatalf("instances should write to different files, both use %s", auth.CurrentFileName())
+	}
+
+	auth.Info("auth message")
+	billing.WithField("order", 42).Info("billing message")
+
+	if err := auth.Close(); err != nil {
+		t.Errorf("auth.Close() returned error: %v", err)
+	}
+	if err := billing.Close(); err != nil {
+		t.Errorf("billing.Close() returned error: %v", err)
+	}
+
+	authContent, err := os.ReadFile(filepath.Join(root, "auth.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	billingContent, err := os.ReadFile(filepath.Join(root, "billing.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(authContent), "auth message") || strings.Contains(string(authContent), "billing message") {
+		t.Errorf("unexpected auth log content: %q", authContent)
+	}
+	if !strings.Contains(string(billingContent), "billing message order=42") || strings.Contains(string(billingContent), "auth message") {
+		t.Errorf("unexpected billing log content: %q", billingContent)
+	}
+}
+
+// TestLoggerCloseIdempotent 测试实例重复关闭是安全的
+func TestLoggerCloseIdempotent(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-instance-close")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "close_test"
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Info("close test message")
+	if l.CurrentFileName() == "" {
+		t.Error("CurrentFileName should not be empty before Close")
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("first Close() returned error: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("second Close() returned error: %v", err)
+	}
+	if l.CurrentFileName() != "" {
+		t.Errorf("CurrentFileName should be empty after Close, got %s", l.CurrentFileName())
+	}
+}
+
+// TestDefaultInstance 测试包级函数委托给默认实例
+func TestDefaultInstance(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "default_test"
+	if err := SetLoggerSettingsWithError(settings); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	if Default().CurrentFileName() != CurrentFileName() {
+		t.Errorf("Default().CurrentFileName() = %s, want %s", Default().CurrentFileName(), CurrentFileName())
+	}
+
+	logger, err := GetLogger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger != Default().Logger {
+		t.Error("GetLogger() should return the logrus logger of the default instance")
+	}
+}
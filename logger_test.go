@@ -1,19 +1,13 @@
 package logger
 
 import (
-	"sync"
 	"testing"
-	"github.com/sirupsen/logrus"
-	"github.com/lestrrat-go/file-rotatelogs"
 )
 
 // testStateBackup 安全地备份和恢复logger状态
 // 用于测试中避免竞态条件
 type testStateBackup struct {
-	loggerBase          *logrus.Logger
-	rotateLogsWriter    *rotatelogs.RotateLogs
-	currentLogFileFPath string
-	// 移除: loggerOnce sync.Once - sync.Once不应该被复制，应重新初始化
+	defaultLogger *Logger
 }
 
 // backupState 安全备份当前状态
@@ -23,9 +17,7 @@ func backupState() *testStateBackup {
 	defer loggerMutex.Unlock()
 
 	return &testStateBackup{
-		loggerBase:          loggerBase,
-		rotateLogsWriter:    rotateLogsWriter,
-		currentLogFileFPath: currentLogFileFPath,
+		defaultLogger: defaultLogger,
 	}
 }
 
@@ -35,11 +27,7 @@ func (b *testStateBackup) restoreState() {
 	loggerMutex.Lock()
 	defer loggerMutex.Unlock()
 
-	loggerBase = b.loggerBase
-	rotateLogsWriter = b.rotateLogsWriter
-	currentLogFileFPath = b.currentLogFileFPath
-	// 重置 loggerOnly 标记
-	loggerOnce = sync.Once{}
+	defaultLogger = b.defaultLogger
 }
 
 // resetState 完全重置 logger 状态
@@ -48,11 +36,10 @@ func resetState() {
 	loggerMutex.Lock()
 	defer loggerMutex.Unlock()
 
-	_ = closeOldResources() // 忽略错误，因为这是测试清理代码
-	loggerBase = nil
-	rotateLogsWriter = nil
-	currentLogFileFPath = ""
-	loggerOnce = sync.Once{}
+	if defaultLogger != nil {
+		_ = defaultLogger.Close() // 忽略错误，因为这是测试清理代码
+	}
+	defaultLogger = nil
 }
 
 // withBackup 提供标准的测试模式
@@ -61,4 +48,4 @@ func withBackup(t *testing.T, testFunc func()) {
 	backup := backupState()
 	defer backup.restoreState()
 	testFunc()
-}
\ No newline at end of file
+}
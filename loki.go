@@ -0,0 +1,293 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Loki 推送请求体的编码
+	LokiEncodingJSON     = "json"     // JSON，可使用 gzip 压缩（默认）
+	LokiEncodingProtobuf = "protobuf" // snappy 压缩的 protobuf（logproto.PushRequest）
+
+	lokiPushPath          = "/loki/api/v1/push"
+	lokiMaxLabelValuesDef = 100
+)
+
+// LokiSettings Grafana Loki 推送输出配置
+type LokiSettings struct {
+	URL            string            // Loki 地址，如 "http://loki:3100"，未包含路径时使用 /loki/api/v1/push
+	Labels         map[string]string // 静态标签（默认 job=<LogNameBase>）
+	LabelKeys      []string          // 提升为标签的字段名，"level" 未在字段中设置时取条目级别
+	MaxLabelValues int               // 每个字段标签最多的不同取值数（默认 100），超出后新取值不再作为标签
+	TenantID       string            // 多租户时的 X-Scope-OrgID
+	Headers        map[string]string // 附加的请求头，如认证信息
+	FormatterType  string            // 日志行使用的格式器类型，为空时与文件输出相同
+	Encoding       string            // 请求体编码：json（默认）或 protobuf
+	DisableGzip    bool              // 禁用 JSON 请求体的 gzip 压缩，protobuf 请求体总是使用 snappy 压缩
+	Timeout        time.Duration     // 单次请求超时（默认 10s）
+	BatchSettings                    // 批量与重试配置
+}
+
+// validate 验证 Loki 输出配置
+func (s *LokiSettings) validate() error {
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid loki url: %q", s.URL)
+	}
+	if s.MaxLabelValues < 0 || s.Timeout < 0 {
+		return fmt.Errorf("loki limits cannot be negative")
+	}
+	switch s.Encoding {
+	case "", LokiEncodingJSON, LokiEncodingProtobuf:
+	default:
+		return fmt.Errorf("unknown loki encoding: %s", s.Encoding)
+	}
+
+	// 标签名中的非法字符替换为下划线后，不同的名称（如 "a.b" 和 "a_b"）可能得到同一个标签名
+	names := make(map[string]string, len(s.Labels)+len(s.LabelKeys))
+	static := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		static = append(static, k)
+	}
+	sort.Strings(static)
+	for _, k := range append(static, s.LabelKeys...) {
+		name := lokiLabelName(k)
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("loki labels %q and %q both map to label name %q", prev, k, name)
+		}
+		names[name] = k
+	}
+	return s.BatchSettings.validate()
+}
+
+// lokiSink 将条目按标签分流、攒批后推送到 Loki
+// 队列中的记录已按推送格式编码：JSON 时键为标签的 JSON 对象，值为 [时间, 日志行]；
+// protobuf 时键为 {name="value", ...} 形式的标签，值为编码后的 EntryAdapter
+type lokiSink struct {
+	formatter      logrus.Formatter
+	protobuf       bool
+	labels         map[string]string
+	labelKeys      []string
+	maxLabelValues int
+	poster         *httpPoster
+	*batcher
+
+	mu     sync.Mutex
+	values map[string]map[string]bool // 每个字段标签已出现的取值，用于限制基数
+}
+
+func newLokiSink(s *LokiSettings, settings *Settings) (*lokiSink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	w := &lokiSink{
+		formatter:      sinkFormatter(settings, s.FormatterType),
+		labels:         make(map[string]string),
+		protobuf:       s.Encoding == LokiEncodingProtobuf,
+		maxLabelValues: s.MaxLabelValues,
+		values:         make(map[string]map[string]bool),
+	}
+	for k, v := range s.Labels {
+		w.labels[lokiLabelName(k)] = v
+	}
+	if len(w.labels) == 0 {
+		// Loki 要求每个流至少有一个标签
+		w.labels["job"] = settings.LogNameBase
+	}
+	w.labelKeys = append(w.labelKeys, s.LabelKeys...)
+	if w.maxLabelValues == 0 {
+		w.maxLabelValues = lokiMaxLabelValuesDef
+	}
+
+	pushURL := s.URL
+	if u, _ := url.Parse(s.URL); u.Path == "" || u.Path == "/" {
+		pushURL = strings.TrimSuffix(s.URL, "/") + lokiPushPath
+	}
+	headers := make(map[string]string, len(s.Headers)+1)
+	for k, v := range s.Headers {
+		headers[k] = v
+	}
+	if s.TenantID != "" {
+		headers["X-Scope-OrgID"] = s.TenantID
+	}
+
+	w.batcher = newBatcher(s.BatchSettings, w.send, nil)
+	w.poster = newHTTPPoster(pushURL, headers, !s.DisableGzip && !w.protobuf, s.Timeout, s.BatchSettings, &w.batcher.stats)
+	return w, nil
+}
+
+// Send 实现 sink 接口
+func (w *lokiSink) Send(entry *logrus.Entry) error {
+	line, err := w.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	line = bytes.TrimRight(line, "\n")
+
+	if w.protobuf {
+		// EntryAdapter：timestamp = 1（google.protobuf.Timestamp），line = 2
+		var ts []byte
+		ts = appendProtoVarint(ts, 1, uint64(entry.Time.Unix()))
+		ts = appendProtoVarint(ts, 2, uint64(entry.Time.Nanosecond()))
+		value := appendProtoBytes(nil, 1, ts)
+		value = appendProtoString(value, 2, string(line))
+		return w.add(batchRecord{key: lokiLabelString(w.streamLabels(entry)), data: value})
+	}
+
+	// 流标签序列化为 JSON 对象作为分组键，encoding/json 按键排序，相同标签得到相同的键
+	key, err := json.Marshal(w.streamLabels(entry))
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal([2]string{
+		strconv.FormatInt(entry.Time.UnixNano(), 10),
+		string(line),
+	})
+	if err != nil {
+		return err
+	}
+	return w.add(batchRecord{key: string(key), data: value})
+}
+
+// streamLabels 返回条目所属流的标签：静态标签加上未超过基数上限的字段标签
+// 字段标签与已有标签同名时（如与默认的 job 标签同名）保留已有的值
+func (w *lokiSink) streamLabels(entry *logrus.Entry) map[string]string {
+	labels := make(map[string]string, len(w.labels)+len(w.labelKeys))
+	for k, v := range w.labels {
+		labels[k] = v
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, key := range w.labelKeys {
+		var value string
+		if v, ok := entry.Data[key]; ok {
+			value = fieldString(v)
+		} else if key == "level" {
+			value = entry.Level.String()
+		} else {
+			continue
+		}
+		name := lokiLabelName(key)
+		if _, ok := labels[name]; ok {
+			continue
+		}
+
+		seen := w.values[key]
+		if seen == nil {
+			seen = make(map[string]bool)
+			w.values[key] = seen
+		}
+		if !seen[value] {
+			if len(seen) >= w.maxLabelValues {
+				continue
+			}
+			seen[value] = true
+		}
+		labels[name] = value
+	}
+	return labels
+}
+
+// send 按流分组后推送，流的顺序与条目首次出现的顺序一致
+func (w *lokiSink) send(batch []batchRecord) error {
+	if w.protobuf {
+		return w.sendProtobuf(batch)
+	}
+
+	type stream struct {
+		Stream json.RawMessage   `json:"stream"`
+		Values []json.RawMessage `json:"values"`
+	}
+
+	var streams []*stream
+	index := make(map[string]*stream)
+	for _, rec := range batch {
+		s := index[rec.key]
+		if s == nil {
+			s = &stream{Stream: json.RawMessage(rec.key)}
+			index[rec.key] = s
+			streams = append(streams, s)
+		}
+		s.Values = append(s.Values, json.RawMessage(rec.data))
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return err
+	}
+	return w.poster.post(body, "application/json")
+}
+
+// sendProtobuf 按流分组后以 snappy 压缩的 protobuf 推送
+// PushRequest：streams = 1；StreamAdapter：labels = 1，entries = 2
+func (w *lokiSink) sendProtobuf(batch []batchRecord) error {
+	var keys []string
+	entries := make(map[string][]byte)
+	for _, rec := range batch {
+		if _, ok := entries[rec.key]; !ok {
+			keys = append(keys, rec.key)
+		}
+		entries[rec.key] = appendProtoBytes(entries[rec.key], 2, rec.data)
+	}
+
+	var body []byte
+	for _, key := range keys {
+		stream := appendProtoString(nil, 1, key)
+		stream = append(stream, entries[key]...)
+		body = appendProtoBytes(body, 1, stream)
+	}
+	return w.poster.post(snappy.Encode(nil, body), "application/x-protobuf")
+}
+
+func (w *lokiSink) sinkName() string {
+	return "loki"
+}
+
+// lokiLabelString 将标签按名称排序后格式化为 {name="value", ...}，值按 Go 字符串字面量转义
+func lokiLabelString(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// lokiLabelName 标签名只能包含字母、数字和下划线，且不能以数字开头
+func lokiLabelName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
@@ -0,0 +1,409 @@
+package logger
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+)
+
+// lokiPush /loki/api/v1/push 的请求体
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+// fakeLoki 记录收到的推送请求，protobuf 请求转换为与 JSON 相同的结构
+type fakeLoki struct {
+	mu      sync.Mutex
+	pushes  []lokiPush
+	paths   []string
+	tenants []string
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		p   lokiPush
+		err error
+	)
+	switch r.Header.Get("Content-Type") {
+	case "application/x-protobuf":
+		p, err = decodeLokiProtobuf(r.Body)
+	case "application/json":
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			if body, err = gzip.NewReader(r.Body); err != nil {
+				break
+			}
+		}
+		err = json.NewDecoder(body).Decode(&p)
+	default:
+		err = fmt.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pushes = append(f.pushes, p)
+	f.paths = append(f.paths, r.URL.Path)
+	f.tenants = append(f.tenants, r.Header.Get("X-Scope-OrgID"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeLokiProtobuf 解码 snappy 压缩的 PushRequest
+func decodeLokiProtobuf(r io.Reader) (lokiPush, error) {
+	var p lokiPush
+	compressed, err := io.ReadAll(r)
+	if err != nil {
+		return p, err
+	}
+	body, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return p, err
+	}
+
+	for _, stream := range protoFields(body, 1) {
+		var s struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		}
+		labels := protoFields(stream, 1)
+		if len(labels) != 1 {
+			return p, fmt.Errorf("stream without labels")
+		}
+		if s.Stream, err = parseLokiLabels(string(labels[0])); err != nil {
+			return p, err
+		}
+		for _, entry := range protoFields(stream, 2) {
+			ts := protoFields(entry, 1)
+			line := protoFields(entry, 2)
+			if len(ts) != 1 || len(line) != 1 {
+				return p, fmt.Errorf("invalid entry")
+			}
+			sec, nsec := protoVarint(ts[0], 1), protoVarint(ts[0], 2)
+			s.Values = append(s.Values, [2]string{
+				strconv.FormatInt(time.Unix(int64(sec), int64(nsec)).UnixNano(), 10),
+				string(line[0]),
+			})
+		}
+		p.Streams = append(p.Streams, s)
+	}
+	return p, nil
+}
+
+// protoFields 返回消息中编号为 field 的长度前缀字段，跳过其他 varint 字段
+func protoFields(b []byte, field uint64) [][]byte {
+	var out [][]byte
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		b = b[n:]
+		v, n := binary.Uvarint(b)
+		b = b[n:]
+		if tag&7 != protoWireBytes {
+			continue
+		}
+		if tag>>3 == field {
+			out = append(out, b[:v])
+		}
+		b = b[v:]
+	}
+	return out
+}
+
+// protoVarint 返回消息中编号为 field 的 varint 字段，不存在时为 0
+func protoVarint(b []byte, field uint64) uint64 {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		b = b[n:]
+		v, n := binary.Uvarint(b)
+		b = b[n:]
+		if tag&7 == protoWireBytes {
+			b = b[v:]
+			continue
+		}
+		if tag>>3 == field {
+			return v
+		}
+	}
+	return 0
+}
+
+// parseLokiLabels 解析 {name="value", ...} 形式的标签
+func parseLokiLabels(s string) (map[string]string, error) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, fmt.Errorf("invalid labels %q", s)
+	}
+	labels := make(map[string]string)
+	rest := s[1 : len(s)-1]
+	for rest != "" {
+		i := strings.IndexByte(rest, '=')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid labels %q", s)
+		}
+		name, value := rest[:i], rest[i+1:]
+		end := 1
+		for end < len(value) && value[end] != '"' {
+			if value[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(value) {
+			return nil, fmt.Errorf("invalid labels %q", s)
+		}
+		unquoted, err := strconv.Unquote(value[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		labels[name] = unquoted
+		rest = strings.TrimPrefix(value[end+1:], ", ")
+	}
+	return labels, nil
+}
+
+func (f *fakeLoki) snapshot() []lokiPush {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]lokiPush(nil), f.pushes...)
+}
+
+// TestLokiSinkStreams 测试按标签分流、静态标签、级别标签和租户请求头
+func TestLokiSinkStreams(t *testing.T) {
+	f := &fakeLoki{}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "loki"
+		s.FormatterType = FormatterTypeJSON
+		s.Loki = &LokiSettings{
+			URL:           srv.URL,
+			Labels:        map[string]string{"app": "billing", "env-name": "prod"},
+			LabelKeys:     []string{"module", "level"},
+			TenantID:      "team-a",
+			BatchSettings: BatchSettings{BatchInterval: time.Hour},
+		}
+	})
+	l.WithField("module", "db").Info("first")
+	l.WithField("module", "http").Warn("second")
+	l.WithField("module", "db").Info("third")
+	l.Info("no module")
+	flushLogger(t, l)
+
+	pushes := f.snapshot()
+	if len(pushes) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(pushes))
+	}
+	streams := pushes[0].Streams
+	if len(streams) != 3 {
+		t.Fatalf("expected 3 streams, got %+v", streams)
+	}
+
+	db := streams[0]
+	if db.Stream["app"] != "billing" || db.Stream["env_name"] != "prod" || db.Stream["module"] != "db" || db.Stream["level"] != "info" {
+		t.Errorf("unexpected labels: %v", db.Stream)
+	}
+	if len(db.Values) != 2 {
+		t.Fatalf("db stream should hold 2 entries: %v", db.Values)
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(db.Values[1][1]), &line); err != nil || line["msg"] != "third" {
+		t.Errorf("unexpected line %q: %v", db.Values[1][1], err)
+	}
+	if db.Values[0][0] > db.Values[1][0] || len(db.Values[0][0]) < 19 {
+		t.Errorf("timestamps should be ordered unix nanoseconds: %v", db.Values)
+	}
+
+	if streams[1].Stream["module"] != "http" || streams[1].Stream["level"] != "warning" {
+		t.Errorf("unexpected labels: %v", streams[1].Stream)
+	}
+	if _, ok := streams[2].Stream["module"]; ok {
+		t.Errorf("entry without module should not carry the label: %v", streams[2].Stream)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.paths[0] != lokiPushPath || f.tenants[0] != "team-a" {
+		t.Errorf("path = %q, tenant = %q", f.paths[0], f.tenants[0])
+	}
+	if stats := l.DeliveryStats()["loki"]; stats.Sent != 4 || stats.Batches != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+// TestLokiSinkLabelCardinality 测试超过基数上限的新取值不再作为标签
+func TestLokiSinkLabelCardinality(t *testing.T) {
+	f := &fakeLoki{}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "loki"
+		s.FormatterType = FormatterTypeJSON
+		s.Loki = &LokiSettings{
+			URL:            srv.URL + "/custom/push",
+			LabelKeys:      []string{"user"},
+			MaxLabelValues: 2,
+			BatchSettings:  BatchSettings{BatchInterval: time.Hour},
+		}
+	})
+	for _, user := range []string{"a", "b", "c", "d", "a"} {
+		l.WithField("user", user).Info("login")
+	}
+	flushLogger(t, l)
+
+	pushes := f.snapshot()
+	if len(pushes) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(pushes))
+	}
+	counts := make(map[string]int)
+	for _, s := range pushes[0].Streams {
+		if s.Stream["job"] != "loki" {
+			t.Errorf("default job label missing: %v", s.Stream)
+		}
+		counts[s.Stream["user"]] += len(s.Values)
+	}
+	if len(counts) != 3 || counts["a"] != 2 || counts["b"] != 1 || counts[""] != 2 {
+		t.Errorf("unexpected streams: %v", counts)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.paths[0] != "/custom/push" {
+		t.Errorf("explicit path should be kept, got %q", f.paths[0])
+	}
+}
+
+// TestLokiSinkLabelCollision 测试字段标签与默认标签同名时保留默认标签的值
+func TestLokiSinkLabelCollision(t *testing.T) {
+	f := &fakeLoki{}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "loki"
+		s.FormatterType = FormatterTypeJSON
+		s.Loki = &LokiSettings{
+			URL:           srv.URL,
+			LabelKeys:     []string{"job"},
+			BatchSettings: BatchSettings{BatchInterval: time.Hour},
+		}
+	})
+	l.WithField("job", "worker").Info("collide")
+	flushLogger(t, l)
+
+	pushes := f.snapshot()
+	if len(pushes) != 1 || len(pushes[0].Streams) != 1 {
+		t.Fatalf("unexpected pushes: %+v", pushes)
+	}
+	if job := pushes[0].Streams[0].Stream["job"]; job != "loki" {
+		t.Errorf("job label = %q, want the default value loki", job)
+	}
+}
+
+// TestLokiSettingsYAML 测试 YAML 配置和参数校验
+// TestLokiSinkProtobuf 测试 snappy 压缩的 protobuf 推送
+func TestLokiSinkProtobuf(t *testing.T) {
+	f := &fakeLoki{}
+	var gzipped int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "" {
+			atomic.StoreInt32(&gzipped, 1)
+		}
+		f.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "loki"
+		s.FormatterType = FormatterTypeText
+		s.Loki = &LokiSettings{
+			URL:           srv.URL,
+			Labels:        map[string]string{"app": "billing"},
+			LabelKeys:     []string{"module"},
+			Encoding:      LokiEncodingProtobuf,
+			BatchSettings: BatchSettings{BatchInterval: time.Hour},
+		}
+	})
+	l.WithField("module", `d"b`).Info("first")
+	l.WithField("module", "http").Warn("second")
+	l.WithField("module", `d"b`).Info("third")
+	flushLogger(t, l)
+
+	pushes := f.snapshot()
+	if len(pushes) != 1 {
+		t.Fatalf("expected 1 push, got %d", len(pushes))
+	}
+	if atomic.LoadInt32(&gzipped) != 0 {
+		t.Error("protobuf push should not set Content-Encoding")
+	}
+	streams := pushes[0].Streams
+	if len(streams) != 2 {
+		t.Fatalf("expected 2 streams, got %+v", streams)
+	}
+	db := streams[0]
+	if db.Stream["app"] != "billing" || db.Stream["module"] != `d"b` || len(db.Values) != 2 {
+		t.Fatalf("unexpected stream: %+v", db)
+	}
+	if !strings.Contains(db.Values[1][1], "third") || strings.HasSuffix(db.Values[1][1], "\n") {
+		t.Errorf("unexpected line %q", db.Values[1][1])
+	}
+	if db.Values[0][0] > db.Values[1][0] || len(db.Values[0][0]) < 19 {
+		t.Errorf("timestamps should be ordered unix nanoseconds: %v", db.Values)
+	}
+	if streams[1].Stream["module"] != "http" || !strings.Contains(streams[1].Values[0][1], "second") {
+		t.Errorf("unexpected stream: %+v", streams[1])
+	}
+}
+
+func TestLokiSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+loki:
+  url: http://loki:3100
+  labels:
+    app: billing
+  label_keys: [module, level]
+  max_label_values: 50
+  tenant_id: team-a
+  batch_size: 200
+  encoding: protobuf
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lk := s.Loki
+	if lk == nil || lk.URL != "http://loki:3100" || lk.Labels["app"] != "billing" || len(lk.LabelKeys) != 2 ||
+		lk.MaxLabelValues != 50 || lk.TenantID != "team-a" || lk.BatchSize != 200 || lk.Encoding != LokiEncodingProtobuf {
+		t.Fatalf("Loki = %+v", lk)
+	}
+
+	for _, bad := range []*LokiSettings{
+		{URL: "loki:3100"},
+		{URL: "http://loki:3100", Encoding: "xml"},
+		{URL: "http://loki:3100", MaxLabelValues: -1},
+		{URL: "http://loki:3100", LabelKeys: []string{"a.b", "a_b"}},
+		{URL: "http://loki:3100", Labels: map[string]string{"env": "prod"}, LabelKeys: []string{"env"}},
+	} {
+		settings := NewSettings()
+		settings.Loki = bad
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ModuleFieldKey 用于按模块覆盖日志级别的字段名
+const ModuleFieldKey = "module"
+
+// moduleLevelFilter 按模块覆盖日志级别
+// 模块名取自 module 字段，未设置该字段且开启调用者信息时取调用者的包路径。
+// 配置了按级别路由时，logrus 的级别还需要满足路由接收的最详细级别，主文件和其他输出仍按本过滤器过滤
+type moduleLevelFilter struct {
+	base       uint32 // 未匹配任何规则时使用的级别（logrus.Level）
+	rules      map[string]logrus.Level
+	routeLevel logrus.Level // 路由接收的最详细级别，没有路由时为 PanicLevel
+}
+
+func newModuleLevelFilter(base logrus.Level, rules map[string]logrus.Level) *moduleLevelFilter {
+	f := &moduleLevelFilter{rules: make(map[string]logrus.Level, len(rules))}
+	for k, v := range rules {
+		f.rules[k] = v
+	}
+	f.setBase(base)
+	return f
+}
+
+func (f *moduleLevelFilter) setBase(level logrus.Level) {
+	atomic.StoreUint32(&f.base, uint32(level))
+}
+
+func (f *moduleLevelFilter) baseLevel() logrus.Level {
+	return logrus.Level(atomic.LoadUint32(&f.base))
+}
+
+// mostVerbose 返回基础级别、所有模块级别和路由级别中最详细的一个，用作 logrus 的级别
+func (f *moduleLevelFilter) mostVerbose() logrus.Level {
+	level := f.baseLevel()
+	if f.routeLevel > level {
+		level = f.routeLevel
+	}
+	for _, l := range f.rules {
+		if l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// allows 判断条目是否满足最具体的匹配规则
+func (f *moduleLevelFilter) allows(entry *logrus.Entry) bool {
+	level, ok := f.match(entryModule(entry))
+	if !ok {
+		level = f.baseLevel()
+	}
+	return entry.Level <= level
+}
+
+// ruleAllows 判断条目是否满足匹配到的模块级别规则，未匹配任何规则时返回 true
+func (f *moduleLevelFilter) ruleAllows(entry *logrus.Entry) bool {
+	level, ok := f.match(entryModule(entry))
+	return !ok || entry.Level <= level
+}
+
+// match 查找最具体（最长）的匹配规则
+// 规则 "billing" 匹配 "billing"、"billing.invoice" 和 "billing/invoice"
+func (f *moduleLevelFilter) match(module string) (logrus.Level, bool) {
+	if module == "" {
+		return 0, false
+	}
+
+	var (
+		best    string
+		level   logrus.Level
+		matched bool
+	)
+	for k, v := range f.rules {
+		if len(k) <= len(best) && matched {
+			continue
+		}
+		if module == k || (strings.HasPrefix(module, k) && (module[len(k)] == '.' || module[len(k)] == '/')) {
+			best, level, matched = k, v, true
+		}
+	}
+	return level, matched
+}
+
+// entryModule 返回条目所属的模块名
+func entryModule(entry *logrus.Entry) string {
+	if v, ok := entry.Data[ModuleFieldKey].(string); ok && v != "" {
+		return v
+	}
+	if entry.HasCaller() {
+		return callerPackage(entry.Caller)
+	}
+	return ""
+}
+
+// moduleLevelFormatter 在调用实际格式器前按模块级别过滤条目
+// 被过滤的条目返回空内容，不会写入任何输出
+type moduleLevelFormatter struct {
+	logrus.Formatter
+	filter *moduleLevelFilter
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *moduleLevelFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.filter.allows(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newModuleTestLogger 创建输出到内存的日志器，用于验证模块级别过滤
+func newModuleTestLogger(t *testing.T, settings *Settings) (*Logger, *bytes.Buffer) {
+	root, err := os.MkdirTemp("", "logger-ut-module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	settings.LogRootFPath = root
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	buf := &bytes.Buffer{}
+	l.SetOutput(buf)
+	return l, buf
+}
+
+// TestModuleLevels 测试按 module 字段覆盖日志级别
+func TestModuleLevels(t *testing.T) {
+	settings := NewSettings()
+	settings.Level = logrus.InfoLevel
+	settings.ModuleLevels = map[string]logrus.Level{
+		"billing":    logrus.DebugLevel,
+		"billing.db": logrus.WarnLevel,
+	}
+	l, buf := newModuleTestLogger(t, settings)
+
+	l.WithField(ModuleFieldKey, "billing").Debug("billing debug")
+	l.WithField(ModuleFieldKey, "billing.invoice").Debug("invoice debug")
+	l.WithField(ModuleFieldKey, "billing.db").Info("db info")
+	l.WithField(ModuleFieldKey, "billing.db").Warn("db warn")
+	l.WithField(ModuleFieldKey, "billingx").Debug("billingx debug")
+	l.WithField(ModuleFieldKey, "auth").Debug("auth debug")
+	l.Debug("plain debug")
+	l.Info("plain info")
+
+	output := buf.String()
+	for _, want := range []string{"billing debug", "invoice debug", "db warn", "plain info"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q", want)
+		}
+	}
+	for _, unwanted := range []string{"db info", "billingx debug", "auth debug", "plain debug"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("output should not contain %q", unwanted)
+		}
+	}
+}
+
+// TestModuleLevelsCallerPackage 测试开启调用者信息时按包路径匹配
+func TestModuleLevelsCallerPackage(t *testing.T) {
+	settings := NewSettings()
+	settings.DisableCaller = false
+	settings.ModuleLevels = map[string]logrus.Level{
+		"github.com/WQGroup": logrus.DebugLevel,
+	}
+	l, buf := newModuleTestLogger(t, settings)
+
+	l.Debug("caller package debug")
+
+	output := buf.String()
+	if !strings.Contains(output, "caller package debug") {
+		t.Errorf("debug from matching caller package should be written, got %q", output)
+	}
+	if !strings.Contains(output, "module_level_test.go") {
+		t.Errorf("caller should point to the test file, got %q", output)
+	}
+}
+
+// TestModuleLevelsSetLevel 测试配置模块级别后调整基础级别
+func TestModuleLevelsSetLevel(t *testing.T) {
+	settings := NewSettings()
+	settings.ModuleLevels = map[string]logrus.Level{"billing": logrus.DebugLevel}
+	l, buf := newModuleTestLogger(t, settings)
+
+	l.SetLevel(logrus.ErrorLevel)
+	if l.GetLevel() != logrus.ErrorLevel {
+		t.Errorf("GetLevel() = %v, want error", l.GetLevel())
+	}
+
+	l.Warn("plain warn")
+	l.WithField(ModuleFieldKey, "billing").Debug("billing debug")
+
+	output := buf.String()
+	if strings.Contains(output, "plain warn") {
+		t.Error("warn should be filtered after SetLevel(ErrorLevel)")
+	}
+	if !strings.Contains(output, "billing debug") {
+		t.Error("module override should still allow debug")
+	}
+}
+
+// TestLoadSettingsFromYAMLModuleLevels 测试 YAML 中的 module_levels 配置
+func TestLoadSettingsFromYAMLModuleLevels(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-yaml-module")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "config.yaml")
+	content := "module_levels:\n  billing: debug\n  github.com/acme/db: warn\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettingsFromYAML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.ModuleLevels["billing"] != logrus.DebugLevel || settings.ModuleLevels["github.com/acme/db"] != logrus.WarnLevel {
+		t.Errorf("unexpected module levels: %v", settings.ModuleLevels)
+	}
+}
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMsgpackRoundTrip 测试各类型编码的边界值
+func TestMsgpackRoundTrip(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want interface{}
+	}{
+		{nil, nil},
+		{true, true},
+		{int64(127), int64(127)},
+		{128, uint64(128)},
+		{math.MaxUint32 + 1, uint64(math.MaxUint32 + 1)},
+		{-32, int64(-32)},
+		{-33, int64(-33)},
+		{math.MinInt16, int64(math.MinInt16)},
+		{int64(math.MinInt64), int64(math.MinInt64)},
+		{1.5, 1.5},
+		{"", ""},
+		{strings.Repeat("x", 40), strings.Repeat("x", 40)},
+		{strings.Repeat("y", 70000), strings.Repeat("y", 70000)},
+		{[]byte{1, 2}, []byte{1, 2}},
+		{errors.New("boom"), "boom"},
+		{[]int{1, 2}, []interface{}{int64(1), int64(2)}},
+		{map[string]int{"b": 2, "a": 1}, map[string]interface{}{"a": int64(1), "b": int64(2)}},
+		{struct {
+			Name string `json:"name"`
+		}{"n"}, map[string]interface{}{"name": "n"}},
+	}
+
+	for _, tt := range tests {
+		b := appendMsgpackValue(nil, tt.in)
+		got, err := decodeMsgpack(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			t.Errorf("decode %v: %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("round trip %v = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	networkQueueSizeDef  = 1024
+	networkMaxSpoolMBDef = 100
+	networkMinBackoffDef = 500 * time.Millisecond
+	networkMaxBackoffDef = 30 * time.Second
+	networkDialTimeout   = 5 * time.Second
+	networkWriteTimeout  = 5 * time.Second
+)
+
+// NetworkSettings TCP/UDP 网络输出配置
+type NetworkSettings struct {
+	Network       string // "tcp"（默认）或 "udp"
+	Address       string // 地址，如 "collector:5170"
+	FormatterType string // 发送时使用的格式器类型，为空时与文件输出相同
+
+	// TLS 配置，仅用于 TCP
+	TLS                   bool        // 是否使用 TLS
+	TLSConfig             *tls.Config // 自定义 TLS 配置，设置后忽略下面两项
+	TLSCAFile             string      // 用于校验服务端证书的 CA 文件（PEM）
+	TLSInsecureSkipVerify bool        // 是否跳过证书校验，仅用于测试
+
+	// 连接断开期间条目写入磁盘缓冲，恢复后按顺序重放
+	SpoolDir     string // 缓冲目录（默认 <日志目录>/spool/<LogNameBase>）
+	MaxSpoolMB   int    // 缓冲上限(MB)，超出时丢弃最旧的条目（默认 100）
+	DisableSpool bool   // 禁用磁盘缓冲，连接断开期间的条目被丢弃
+
+	QueueSize  int           // 内存队列长度（默认 1024），队列满时阻塞
+	MinBackoff time.Duration // 首次重连等待时间（默认 500ms），之后每次翻倍
+	MaxBackoff time.Duration // 最长重连等待时间（默认 30s）
+}
+
+// NetworkStats 网络输出统计
+type NetworkStats struct {
+	Sent    uint64 // 已发送的条目数
+	Spooled uint64 // 写入磁盘缓冲的条目数
+	Dropped uint64 // 被丢弃的条目数（未开启缓冲时连接断开期间的条目，或缓冲超过上限时最旧的条目）
+}
+
+// validate 验证网络输出配置
+func (s *NetworkSettings) validate() error {
+	switch s.Network {
+	case "", "tcp", "udp":
+	default:
+		return fmt.Errorf("unknown network: %s", s.Network)
+	}
+	if s.Address == "" {
+		return fmt.Errorf("network address cannot be empty")
+	}
+	if s.TLS && s.Network == "udp" {
+		return fmt.Errorf("TLS is not supported over udp")
+	}
+	if s.MaxSpoolMB < 0 || s.QueueSize < 0 || s.MinBackoff < 0 || s.MaxBackoff < 0 {
+		return fmt.Errorf("network limits cannot be negative")
+	}
+	return nil
+}
+
+// backoff 指数退避
+type backoff struct {
+	min, max time.Duration
+	next     time.Duration
+}
+
+// duration 返回本次等待时间并将下次等待时间翻倍
+func (b *backoff) duration() time.Duration {
+	if b.next < b.min {
+		b.next = b.min
+	}
+	d := b.next
+	if b.next *= 2; b.next > b.max {
+		b.next = b.max
+	}
+	return d
+}
+
+func (b *backoff) reset() {
+	b.next = b.min
+}
+
+// networkSink 将格式化后的条目发送到 TCP/UDP 端点
+// 条目在 Send 中格式化后放入内存队列，由后台协程发送。连接断开时条目写入磁盘缓冲，
+// 后台协程按指数退避重连，连接恢复后先按顺序重放缓冲，再发送新的条目。
+// 重放按“至少一次”语义：进程在重放中途退出时，下次启动会从未删除的段开头重新发送
+type networkSink struct {
+	network   string
+	address   string
+	tlsConfig *tls.Config
+	formatter logrus.Formatter
+	spool     *diskSpool
+	backoff   backoff
+
+	queue chan []byte
+	done  chan struct{}
+	conn  net.Conn // 只由后台协程访问
+
+	mu     sync.RWMutex // 保护 closed，Send 时持有读锁
+	closed bool
+
+	sent    uint64
+	spooled uint64
+	dropped uint64
+}
+
+func newNetworkSink(s *NetworkSettings, settings *Settings) (*networkSink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return startNetworkSink(s, sinkFormatter(settings, s.FormatterType),
+		filepath.Join(settings.logDir(), "spool", settings.LogNameBase))
+}
+
+// startNetworkSink 使用指定的格式器创建网络输出并启动后台协程，SpoolDir 为空时使用 spoolDir
+func startNetworkSink(s *NetworkSettings, formatter logrus.Formatter, spoolDir string) (*networkSink, error) {
+	w := &networkSink{
+		network:   s.Network,
+		address:   s.Address,
+		formatter: formatter,
+		backoff:   backoff{min: s.MinBackoff, max: s.MaxBackoff},
+		done:      make(chan struct{}),
+	}
+	if w.network == "" {
+		w.network = "tcp"
+	}
+	if w.backoff.min == 0 {
+		w.backoff.min = networkMinBackoffDef
+	}
+	if w.backoff.max == 0 {
+		w.backoff.max = networkMaxBackoffDef
+	}
+	size := s.QueueSize
+	if size == 0 {
+		size = networkQueueSizeDef
+	}
+	w.queue = make(chan []byte, size)
+
+	if s.TLS {
+		cfg, err := s.tlsClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		w.tlsConfig = cfg
+	}
+
+	if !s.DisableSpool {
+		dir := s.SpoolDir
+		if dir == "" {
+			dir = spoolDir
+		}
+		maxMB := s.MaxSpoolMB
+		if maxMB == 0 {
+			maxMB = networkMaxSpoolMBDef
+		}
+		spool, err := openDiskSpool(dir, int64(maxMB)*1024*1024)
+		if err != nil {
+			return nil, err
+		}
+		w.spool = spool
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// tlsClientConfig 根据配置创建 TLS 客户端配置
+func (s *NetworkSettings) tlsClientConfig() (*tls.Config, error) {
+	if s.TLSConfig != nil {
+		return s.TLSConfig.Clone(), nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: s.TLSInsecureSkipVerify} // 仅在用户显式开启时跳过校验
+	if host, _, err := net.SplitHostPort(s.Address); err == nil {
+		cfg.ServerName = host
+	}
+	if s.TLSCAFile != "" {
+		pem, err := os.ReadFile(s.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS CA file failed: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", s.TLSCAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// sinkFormatter 创建输出使用的格式器，formatterType 为空时与文件输出相同
+func sinkFormatter(settings *Settings, formatterType string) logrus.Formatter {
+	fs := *settings
+	// 模板按输出自身的设置确定，切换格式器类型后 LogFormat 不再作为模板使用
+	fs.TemplateFormat, _ = settings.templateFormat()
+	if formatterType != "" {
+		fs.FormatterType = formatterType
+		fs.CustomFormatter = nil
+		fs.OnlyMsg = false
+	}
+	return (&FormatterFactory{}).CreateFormatter(&fs)
+}
+
+// Send 实现 sink 接口，格式化条目后放入队列，队列满时阻塞
+func (w *networkSink) Send(entry *logrus.Entry) error {
+	b, err := w.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	// 格式器可能复用缓冲区，必须复制
+	rec := append([]byte(nil), b...)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errors.New("network sink is closed")
+	}
+	w.queue <- rec
+	return nil
+}
+
+func (w *networkSink) run() {
+	defer close(w.done)
+
+	var retry <-chan time.Time
+	if err := w.connect(); err != nil {
+		retry = time.After(w.backoff.duration())
+	}
+
+	for {
+		// 已连接时先按顺序重放磁盘缓冲，期间到达的新条目追加到缓冲末尾
+		for w.conn != nil && w.spool != nil && !w.spool.empty() {
+			w.replayNext()
+			w.spoolQueued()
+		}
+		if w.conn == nil && retry == nil {
+			retry = time.After(w.backoff.duration())
+		}
+
+		select {
+		case rec, ok := <-w.queue:
+			if !ok {
+				w.shutdown()
+				return
+			}
+			w.deliver(rec)
+		case <-retry:
+			retry = nil
+			if err := w.connect(); err == nil {
+				w.backoff.reset()
+			}
+		}
+	}
+}
+
+// connect 建立连接
+func (w *networkSink) connect() error {
+	dialer := &net.Dialer{Timeout: networkDialTimeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if w.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(dialer, w.network, w.address, w.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(w.network, w.address)
+	}
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *networkSink) disconnect() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// write 通过当前连接发送一条记录，失败时断开连接
+func (w *networkSink) write(rec []byte) bool {
+	_ = w.conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+	if _, err := w.conn.Write(rec); err != nil {
+		w.disconnect()
+		return false
+	}
+	atomic.AddUint64(&w.sent, 1)
+	return true
+}
+
+// deliver 发送一条新条目，未连接或缓冲中还有更早的条目时写入缓冲
+func (w *networkSink) deliver(rec []byte) {
+	if w.conn != nil && (w.spool == nil || w.spool.empty()) && w.write(rec) {
+		return
+	}
+	w.spoolRecord(rec)
+}
+
+func (w *networkSink) spoolRecord(rec []byte) {
+	if w.spool == nil {
+		atomic.AddUint64(&w.dropped, 1)
+		return
+	}
+	dropped, err := w.spool.append(rec)
+	atomic.AddUint64(&w.dropped, uint64(dropped))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to spool log entry: %v\n", err)
+		atomic.AddUint64(&w.dropped, 1)
+		return
+	}
+	atomic.AddUint64(&w.spooled, 1)
+}
+
+// spoolQueued 将队列中已有的条目追加到缓冲，避免重放期间阻塞调用方
+func (w *networkSink) spoolQueued() {
+	for {
+		select {
+		case rec, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			w.spoolRecord(rec)
+		default:
+			return
+		}
+	}
+}
+
+// replayNext 重放缓冲中最旧的一条记录
+func (w *networkSink) replayNext() {
+	rec, err := w.spool.peek()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read spooled log entry: %v\n", err)
+		w.disconnect()
+		return
+	}
+	if rec == nil {
+		return
+	}
+	if w.write(rec) {
+		w.spool.advance()
+	}
+}
+
+// shutdown 队列关闭后保存剩余的缓冲并断开连接
+func (w *networkSink) shutdown() {
+	if w.spool != nil {
+		_ = w.spool.Close()
+	}
+	w.disconnect()
+}
+
+// Stats 返回发送统计
+func (w *networkSink) Stats() NetworkStats {
+	return NetworkStats{
+		Sent:    atomic.LoadUint64(&w.sent),
+		Spooled: atomic.LoadUint64(&w.spooled),
+		Dropped: atomic.LoadUint64(&w.dropped),
+	}
+}
+
+// Close 实现 io.Closer 接口，等待队列中的条目发送或写入缓冲
+func (w *networkSink) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
@@ -0,0 +1,319 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lineServer 接收按行分隔的日志的 TCP 服务端
+type lineServer struct {
+	ln    net.Listener
+	lines chan string
+}
+
+func newLineServer(t *testing.T, ln net.Listener) *lineServer {
+	s := &lineServer{ln: ln, lines: make(chan string, 1024)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				scanner := bufio.NewScanner(c)
+				for scanner.Scan() {
+					s.lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+// next 读取下一行并解析 JSON 中的 msg 字段
+func (s *lineServer) next(t *testing.T) string {
+	select {
+	case line := <-s.lines:
+		var v map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		msg, _ := v["msg"].(string)
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for log line")
+		return ""
+	}
+}
+
+// TestNetworkSinkTCP 测试使用指定格式器通过 TCP 发送
+func TestNetworkSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newLineServer(t, ln)
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.Network = &NetworkSettings{
+			Address:       ln.Addr().String(),
+			FormatterType: FormatterTypeJSON,
+			DisableSpool:  true,
+		}
+	})
+	l.WithField("order", 7).Info("shipped")
+
+	if msg := srv.next(t); msg != "shipped" {
+		t.Errorf("msg = %q, want shipped", msg)
+	}
+}
+
+// TestNetworkSinkSpoolReplay 测试连接断开期间写入缓冲，恢复后按顺序重放
+func TestNetworkSinkSpoolReplay(t *testing.T) {
+	// 先占用再释放一个端口，此时连接会被拒绝
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.Network = &NetworkSettings{
+			Address:       addr,
+			FormatterType: FormatterTypeJSON,
+			MinBackoff:    10 * time.Millisecond,
+			MaxBackoff:    50 * time.Millisecond,
+		}
+	})
+	for i := 0; i < 5; i++ {
+		l.Infof("offline %d", i)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for l.NetworkStats().Spooled < 5 {
+		if time.Now().After(deadline) {
+			t.Fatalf("entries were not spooled: %+v", l.NetworkStats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	srv := newLineServer(t, ln)
+
+	for i := 0; i < 5; i++ {
+		if msg, want := srv.next(t), fmt.Sprintf("offline %d", i); msg != want {
+			t.Fatalf("replayed msg = %q, want %q", msg, want)
+		}
+	}
+	l.Info("online")
+	if msg := srv.next(t); msg != "online" {
+		t.Errorf("msg = %q, want online", msg)
+	}
+	if stats := l.NetworkStats(); stats.Sent != 6 || stats.Dropped != 0 {
+		t.Errorf("stats = %+v, want 6 sent and none dropped", stats)
+	}
+}
+
+// TestNetworkSinkSpoolSurvivesRestart 测试关闭时未发送的条目保留在磁盘上，下次启动后重放
+func TestNetworkSinkSpoolSurvivesRestart(t *testing.T) {
+	spoolDir, err := os.MkdirTemp("", "logger-ut-spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(spoolDir)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	settings := NewSettings()
+	settings.LogNameBase = "net"
+	ns := &NetworkSettings{Address: addr, FormatterType: FormatterTypeJSON, SpoolDir: spoolDir}
+
+	w, err := newNetworkSink(ns, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := logrus.NewEntry(logrus.New())
+	entry.Time = time.Now()
+	entry.Level = logrus.InfoLevel
+	entry.Message = "before restart"
+	if err := w.Send(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	srv := newLineServer(t, ln)
+
+	w, err = newNetworkSink(ns, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if msg := srv.next(t); msg != "before restart" {
+		t.Errorf("msg = %q, want before restart", msg)
+	}
+}
+
+// TestNetworkSinkTLS 测试通过 TLS 发送
+func TestNetworkSinkTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(nil)
+	defer ts.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", ts.TLS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newLineServer(t, ln)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.Network = &NetworkSettings{
+			Address:       ln.Addr().String(),
+			FormatterType: FormatterTypeJSON,
+			TLS:           true,
+			TLSConfig:     &tls.Config{RootCAs: pool},
+			DisableSpool:  true,
+		}
+	})
+	l.Info("encrypted")
+
+	if msg := srv.next(t); msg != "encrypted" {
+		t.Errorf("msg = %q, want encrypted", msg)
+	}
+}
+
+// TestNetworkSinkUDP 测试每条日志作为一个 UDP 数据报发送
+func TestNetworkSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.Network = &NetworkSettings{
+			Network:       "udp",
+			Address:       conn.LocalAddr().String(),
+			FormatterType: FormatterTypeText,
+			DisableSpool:  true,
+		}
+	})
+	l.Warn("datagram")
+
+	if msg := readPacket(t, conn); msg == "" || msg[len(msg)-1] != '\n' {
+		t.Errorf("unexpected datagram %q", msg)
+	}
+}
+
+// TestDiskSpool 测试磁盘缓冲的顺序、上限和重新打开
+func TestDiskSpool(t *testing.T) {
+	dir, err := os.MkdirTemp("", "logger-ut-disk-spool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 每条记录 4+96 字节，上限 400 字节，每段 100 字节
+	s, err := openDiskSpool(dir, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropped := 0
+	for i := 0; i < 8; i++ {
+		n, err := s.append([]byte(fmt.Sprintf("%-96d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropped += n
+	}
+	if dropped != 4 {
+		t.Errorf("dropped = %d, want 4", dropped)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = openDiskSpool(dir, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for i := 4; i < 8; i++ {
+		rec, err := s.peek()
+		if err != nil || rec == nil {
+			t.Fatalf("peek %d: rec=%q err=%v", i, rec, err)
+		}
+		if want := fmt.Sprintf("%-96d", i); string(rec) != want {
+			t.Errorf("record = %q, want %q", rec, want)
+		}
+		s.advance()
+	}
+	if !s.empty() {
+		t.Error("spool should be empty")
+	}
+	if rec, _ := s.peek(); rec != nil {
+		t.Errorf("unexpected record %q", rec)
+	}
+}
+
+// TestNetworkSettingsYAML 测试 YAML 配置和参数校验
+func TestNetworkSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+network:
+  network: tcp
+  address: collector:5170
+  formatter_type: json
+  tls: true
+  max_spool_mb: 20
+  min_backoff: 200ms
+  max_backoff: 1m
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := s.Network
+	if n == nil || n.Address != "collector:5170" || !n.TLS || n.MaxSpoolMB != 20 ||
+		n.MinBackoff != 200*time.Millisecond || n.MaxBackoff != time.Minute {
+		t.Fatalf("Network = %+v", n)
+	}
+
+	for _, bad := range []*NetworkSettings{
+		{Network: "sctp", Address: "x:1"},
+		{Network: "tcp"},
+		{Network: "udp", Address: "x:1", TLS: true},
+		{Address: "x:1", MaxSpoolMB: -1},
+	} {
+		settings := NewSettings()
+		settings.Network = bad
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	otlpLogsPath  = "/v1/logs"
+	otlpScopeName = "github.com/WQGroup/logger"
+)
+
+// OTLPSettings OpenTelemetry OTLP/HTTP 日志导出配置，使用 JSON 编码
+type OTLPSettings struct {
+	Endpoint           string            // 收集端地址，如 "http://otel-collector:4318"，未包含路径时使用 /v1/logs
+	Headers            map[string]string // 附加的请求头，如认证信息
+	ServiceName        string            // 资源属性 service.name（默认 LogNameBase）
+	ResourceAttributes map[string]string // 其他资源属性，如 deployment.environment
+	TraceExtractor     TraceExtractor    // 从 context 中提取追踪上下文，可选
+	DisableGzip        bool              // 禁用请求体 gzip 压缩
+	Timeout            time.Duration     // 单次请求超时（默认 10s）
+	BatchSettings                        // 批量与重试配置
+}
+
+// validate 验证 OTLP 输出配置
+func (s *OTLPSettings) validate() error {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid otlp endpoint: %q", s.Endpoint)
+	}
+	if s.Timeout < 0 {
+		return fmt.Errorf("otlp timeout cannot be negative")
+	}
+	return s.BatchSettings.validate()
+}
+
+// otlpSink 将条目转换为 OTLP LogRecord 后攒批导出
+type otlpSink struct {
+	resource json.RawMessage
+	extract  TraceExtractor
+	poster   *httpPoster
+	*batcher
+}
+
+func newOTLPSink(s *OTLPSettings, settings *Settings) (*otlpSink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string]string, len(s.ResourceAttributes)+1)
+	for k, v := range s.ResourceAttributes {
+		attrs[k] = v
+	}
+	attrs["service.name"] = settings.LogNameBase
+	if s.ServiceName != "" {
+		attrs["service.name"] = s.ServiceName
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	resAttrs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		resAttrs = append(resAttrs, otlpKeyValue{Key: k, Value: otlpAnyValue(attrs[k])})
+	}
+	resource, err := json.Marshal(map[string]interface{}{"attributes": resAttrs})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := s.Endpoint
+	if u, _ := url.Parse(s.Endpoint); u.Path == "" || u.Path == "/" {
+		endpoint = strings.TrimSuffix(s.Endpoint, "/") + otlpLogsPath
+	}
+
+	w := &otlpSink{resource: resource, extract: s.TraceExtractor}
+	w.batcher = newBatcher(s.BatchSettings, w.send, nil)
+	w.poster = newHTTPPoster(endpoint, s.Headers, !s.DisableGzip, s.Timeout, s.BatchSettings, &w.batcher.stats)
+	return w, nil
+}
+
+// otlpKeyValue OTLP 的 KeyValue
+type otlpKeyValue struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+// otlpLogRecord OTLP 的 LogRecord，按 OTLP/JSON 的约定 64 位整数编码为字符串，ID 编码为十六进制
+type otlpLogRecord struct {
+	TimeUnixNano         string                 `json:"timeUnixNano"`
+	ObservedTimeUnixNano string                 `json:"observedTimeUnixNano"`
+	SeverityNumber       int                    `json:"severityNumber"`
+	SeverityText         string                 `json:"severityText"`
+	Body                 map[string]interface{} `json:"body"`
+	Attributes           []otlpKeyValue         `json:"attributes,omitempty"`
+	TraceID              string                 `json:"traceId,omitempty"`
+	SpanID               string                 `json:"spanId,omitempty"`
+}
+
+// Send 实现 sink 接口
+func (w *otlpSink) Send(entry *logrus.Entry) error {
+	rec := otlpLogRecord{
+		TimeUnixNano:         strconv.FormatInt(entry.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: strconv.FormatInt(time.Now().UnixNano(), 10),
+		SeverityNumber:       otlpSeverity(entry.Level),
+		SeverityText:         strings.ToUpper(entry.Level.String()),
+		Body:                 otlpAnyValue(entry.Message),
+	}
+
+	traceID, spanID := entryTrace(entry, w.extract)
+	if validTraceID(traceID, 16) {
+		rec.TraceID = strings.ToLower(traceID)
+	}
+	if validTraceID(spanID, 8) {
+		rec.SpanID = strings.ToLower(spanID)
+	}
+
+	for _, k := range sortedKeys(entry.Data) {
+		// 已写入追踪上下文的字段不再重复作为属性
+		if (k == TraceIDFieldKey && rec.TraceID != "") || (k == SpanIDFieldKey && rec.SpanID != "") {
+			continue
+		}
+		rec.Attributes = append(rec.Attributes, otlpKeyValue{Key: k, Value: otlpAnyValue(entry.Data[k])})
+	}
+	if entry.HasCaller() {
+		rec.Attributes = append(rec.Attributes,
+			otlpKeyValue{Key: "code.filepath", Value: otlpAnyValue(entry.Caller.File)},
+			otlpKeyValue{Key: "code.lineno", Value: otlpAnyValue(entry.Caller.Line)},
+			otlpKeyValue{Key: "code.function", Value: otlpAnyValue(entry.Caller.Function)},
+		)
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return w.add(batchRecord{data: b})
+}
+
+// send 将一批 LogRecord 包装为 ExportLogsServiceRequest 后导出
+func (w *otlpSink) send(batch []batchRecord) error {
+	records := make([]json.RawMessage, len(batch))
+	for i, rec := range batch {
+		records[i] = rec.data
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": w.resource,
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]string{"name": otlpScopeName},
+				"logRecords": records,
+			}},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	return w.poster.post(body, "application/json")
+}
+
+func (w *otlpSink) sinkName() string {
+	return "otlp"
+}
+
+// otlpSeverity 将 logrus 级别映射为 OTLP SeverityNumber
+func otlpSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 24 // FATAL4
+	case logrus.FatalLevel:
+		return 21 // FATAL
+	case logrus.ErrorLevel:
+		return 17 // ERROR
+	case logrus.WarnLevel:
+		return 13 // WARN
+	case logrus.InfoLevel:
+		return 9 // INFO
+	case logrus.DebugLevel:
+		return 5 // DEBUG
+	default:
+		return 1 // TRACE
+	}
+}
+
+// otlpAnyValue 将字段值转换为 OTLP 的 AnyValue
+// 切片和映射分别转换为 arrayValue 和 kvlistValue，其他类型转换为字符串
+func otlpAnyValue(v interface{}) map[string]interface{} {
+	switch x := v.(type) {
+	case nil:
+		return map[string]interface{}{}
+	case string:
+		return map[string]interface{}{"stringValue": x}
+	case bool:
+		return map[string]interface{}{"boolValue": x}
+	case []byte:
+		return map[string]interface{}{"bytesValue": base64.StdEncoding.EncodeToString(x)}
+	case error, fmt.Stringer:
+		return map[string]interface{}{"stringValue": fieldString(v)}
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"intValue": strconv.FormatInt(rv.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"intValue": strconv.FormatUint(rv.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"doubleValue": rv.Float()}
+	case reflect.Slice, reflect.Array:
+		values := make([]map[string]interface{}, rv.Len())
+		for i := range values {
+			values[i] = otlpAnyValue(rv.Index(i).Interface())
+		}
+		return map[string]interface{}{"arrayValue": map[string]interface{}{"values": values}}
+	case reflect.Map:
+		values := make([]otlpKeyValue, 0, rv.Len())
+		for _, k := range rv.MapKeys() {
+			values = append(values, otlpKeyValue{Key: fmt.Sprint(k.Interface()), Value: otlpAnyValue(rv.MapIndex(k).Interface())})
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+		return map[string]interface{}{"kvlistValue": map[string]interface{}{"values": values}}
+	}
+	return map[string]interface{}{"stringValue": fmt.Sprint(v)}
+}
@@ -0,0 +1,243 @@
+package logger
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// otlpRequest ExportLogsServiceRequest 的 JSON 编码中测试用到的部分
+type otlpRequest struct {
+	ResourceLogs []struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			LogRecords []otlpLogRecord `json:"logRecords"`
+		} `json:"scopeLogs"`
+	} `json:"resourceLogs"`
+}
+
+// fakeOTLPCollector 记录收到的导出请求
+type fakeOTLPCollector struct {
+	mu       sync.Mutex
+	requests []otlpRequest
+	paths    []string
+}
+
+func (c *fakeOTLPCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var req otlpRequest
+	if err := json.NewDecoder(zr).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, req)
+	c.paths = append(c.paths, r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
+
+// attr 按键查找属性值
+func attr(kvs []otlpKeyValue, key string) map[string]interface{} {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// TestOTLPSinkExport 测试级别映射、属性、资源属性和追踪上下文
+func TestOTLPSinkExport(t *testing.T) {
+	c := &fakeOTLPCollector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	const (
+		traceID = "4BF92F3577B34DA6A3CE929D0E0E4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "otlp"
+		s.Level = logrus.TraceLevel
+		s.OTLP = &OTLPSettings{
+			Endpoint:           srv.URL,
+			ResourceAttributes: map[string]string{"deployment.environment": "test"},
+			BatchSettings:      BatchSettings{BatchInterval: time.Hour},
+		}
+	})
+	l.WithFields(map[string]interface{}{
+		"user":    "alice",
+		"attempt": 3,
+		"ratio":   0.5,
+		"ok":      false,
+		"tags":    []string{"a", "b"},
+		"err":     errors.New("boom"),
+	}).WithField(TraceIDFieldKey, traceID).WithField(SpanIDFieldKey, spanID).Warn("payment failed")
+	l.WithContext(ContextWithTrace(context.Background(), traceID, spanID)).Debug("from context")
+	l.WithField(TraceIDFieldKey, "not-hex").Error("bad trace")
+	flushLogger(t, l)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.requests) != 1 || c.paths[0] != otlpLogsPath {
+		t.Fatalf("requests = %d, paths = %v", len(c.requests), c.paths)
+	}
+	rl := c.requests[0].ResourceLogs[0]
+	if v := attr(rl.Resource.Attributes, "service.name"); v["stringValue"] != "otlp" {
+		t.Errorf("service.name = %v", v)
+	}
+	if v := attr(rl.Resource.Attributes, "deployment.environment"); v["stringValue"] != "test" {
+		t.Errorf("deployment.environment = %v", v)
+	}
+	if rl.ScopeLogs[0].Scope.Name != otlpScopeName {
+		t.Errorf("scope = %q", rl.ScopeLogs[0].Scope.Name)
+	}
+
+	records := rl.ScopeLogs[0].LogRecords
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+
+	r := records[0]
+	if r.SeverityNumber != 13 || r.SeverityText != "WARNING" || r.Body["stringValue"] != "payment failed" {
+		t.Errorf("unexpected record: %+v", r)
+	}
+	if r.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || r.SpanID != spanID {
+		t.Errorf("trace = %q, span = %q", r.TraceID, r.SpanID)
+	}
+	if attr(r.Attributes, TraceIDFieldKey) != nil {
+		t.Error("trace_id should not be repeated as an attribute")
+	}
+	if v := attr(r.Attributes, "attempt"); v["intValue"] != "3" {
+		t.Errorf("attempt = %v", v)
+	}
+	if v := attr(r.Attributes, "ratio"); v["doubleValue"] != 0.5 {
+		t.Errorf("ratio = %v", v)
+	}
+	if v := attr(r.Attributes, "ok"); v["boolValue"] != false {
+		t.Errorf("ok = %v", v)
+	}
+	if v := attr(r.Attributes, "err"); v["stringValue"] != "boom" {
+		t.Errorf("err = %v", v)
+	}
+	if v := attr(r.Attributes, "tags"); v["arrayValue"] == nil {
+		t.Errorf("tags = %v", v)
+	}
+	if r.TimeUnixNano == "" || r.TimeUnixNano > r.ObservedTimeUnixNano {
+		t.Errorf("time = %q, observed = %q", r.TimeUnixNano, r.ObservedTimeUnixNano)
+	}
+
+	if r := records[1]; r.SeverityNumber != 5 || r.TraceID == "" || r.SpanID != spanID {
+		t.Errorf("context trace not applied: %+v", r)
+	}
+	if r := records[2]; r.SeverityNumber != 17 || r.TraceID != "" || attr(r.Attributes, TraceIDFieldKey)["stringValue"] != "not-hex" {
+		t.Errorf("invalid trace id should stay an attribute: %+v", r)
+	}
+}
+
+// TestOTLPSinkTraceExtractor 测试自定义追踪上下文提取和 service.name
+func TestOTLPSinkTraceExtractor(t *testing.T) {
+	c := &fakeOTLPCollector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	type spanKey struct{}
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "otlp"
+		s.Level = logrus.TraceLevel
+		s.OTLP = &OTLPSettings{
+			Endpoint:    srv.URL + "/custom",
+			ServiceName: "billing",
+			TraceExtractor: func(ctx context.Context) (string, string) {
+				if ctx.Value(spanKey{}) == nil {
+					return "", ""
+				}
+				return "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331"
+			},
+			BatchSettings: BatchSettings{BatchInterval: time.Hour},
+		}
+	})
+	l.WithContext(context.WithValue(context.Background(), spanKey{}, true)).Info("traced")
+	l.Info("untraced")
+	flushLogger(t, l)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.requests) != 1 || c.paths[0] != "/custom" {
+		t.Fatalf("requests = %d, paths = %v", len(c.requests), c.paths)
+	}
+	rl := c.requests[0].ResourceLogs[0]
+	if v := attr(rl.Resource.Attributes, "service.name"); v["stringValue"] != "billing" {
+		t.Errorf("service.name = %v", v)
+	}
+	records := rl.ScopeLogs[0].LogRecords
+	if records[0].TraceID != "0af7651916cd43dd8448eb211c80319c" || records[0].SpanID != "b7ad6b7169203331" || records[0].SeverityNumber != 9 {
+		t.Errorf("unexpected record: %+v", records[0])
+	}
+	if records[1].TraceID != "" || records[1].SpanID != "" {
+		t.Errorf("untraced record has trace context: %+v", records[1])
+	}
+}
+
+// TestOTLPSettingsYAML 测试 YAML 配置和参数校验
+func TestOTLPSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+otlp:
+  endpoint: http://otel-collector:4318
+  service_name: billing
+  resource_attributes:
+    deployment.environment: prod
+  batch_interval: 2s
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := s.OTLP
+	if o == nil || o.Endpoint != "http://otel-collector:4318" || o.ServiceName != "billing" ||
+		o.ResourceAttributes["deployment.environment"] != "prod" || o.BatchInterval != 2*time.Second {
+		t.Fatalf("OTLP = %+v", o)
+	}
+
+	settings := NewSettings()
+	settings.OTLP = &OTLPSettings{Endpoint: "otel-collector:4318"}
+	if err := validateSettings(settings); err == nil {
+		t.Error("endpoint without scheme should be rejected")
+	}
+}
+
+// otlpTestError 指针接收者的 Error 在 nil 时 panic
+type otlpTestError struct{ msg string }
+
+func (e *otlpTestError) Error() string { return e.msg }
+
+// TestOTLPAnyValueNilError 测试值为 nil 指针的 error 字段不会 panic
+func TestOTLPAnyValueNilError(t *testing.T) {
+	var typedNil *otlpTestError
+	got := otlpAnyValue(typedNil)
+	if got["stringValue"] != "<nil>" {
+		t.Errorf("otlpAnyValue(typed nil error) = %v, want <nil>", got)
+	}
+	if s := fieldString(&otlpTestError{msg: "boom"}); s != "boom" {
+		t.Errorf("fieldString(error) = %q, want boom", s)
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+// 本文件实现 Loki protobuf 推送需要的 Protocol Buffers 编码子集，避免引入额外依赖
+
+// Protocol Buffers 的线路类型
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+// appendProtoUvarint 追加 varint 编码的无符号整数
+func appendProtoUvarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+// appendProtoTag 追加字段编号和线路类型
+func appendProtoTag(b []byte, field, wireType int) []byte {
+	return appendProtoUvarint(b, uint64(field)<<3|uint64(wireType))
+}
+
+// appendProtoVarint 追加 varint 字段，值为 0 时按 proto3 的规则省略
+func appendProtoVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = appendProtoTag(b, field, protoWireVarint)
+	return appendProtoUvarint(b, v)
+}
+
+// appendProtoBytes 追加长度前缀的字段，用于嵌套消息
+func appendProtoBytes(b []byte, field int, p []byte) []byte {
+	b = appendProtoTag(b, field, protoWireBytes)
+	b = appendProtoUvarint(b, uint64(len(p)))
+	return append(b, p...)
+}
+
+// appendProtoString 追加字符串字段
+func appendProtoString(b []byte, field int, s string) []byte {
+	b = appendProtoTag(b, field, protoWireBytes)
+	b = appendProtoUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
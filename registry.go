@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LoggerOverride 命名日志器相对于基础设置的覆盖项
+// 零值字段表示沿用基础设置
+type LoggerOverride struct {
+	LogNameBase     string           // 日志名称（默认使用注册名）
+	Level           *logrus.Level    // 日志级别
+	MaxAgeDays      int              // 日志最大保存天数
+	MaxSizeMB       int              // 文件大小限制(MB)
+	FormatterType   string           // 格式器类型
+	TimestampFormat string           // 时间戳格式
+	LogFormat       string           // 自定义日志格式（用于 easy-formatter）
+	TemplateFormat  string           // template 格式器使用的模板
+	CustomFormatter logrus.Formatter // 用户自定义格式器
+}
+
+var (
+	namedLoggers      = make(map[string]*Logger) // 已创建的命名日志器
+	namedLoggersMutex sync.Mutex                 // 保护 namedLoggers
+)
+
+// Named 返回指定名称的日志器，首次调用时根据默认日志器的设置与 Settings.Loggers 中的覆盖项创建
+// 为保持与包级函数一致，创建失败时返回仅输出到 stderr 的日志器
+// 命名日志器只写入自己的日志文件和控制台，不继承 Syslog、Network、HTTP 等远程输出，
+// 否则每个命名日志器都会各自建立连接，并与默认日志器争用同一个磁盘缓冲目录
+func Named(name string) *Logger {
+	l, err := NamedWithError(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create logger %q: %v\n", name, err)
+		return newFallbackLogger()
+	}
+	return l
+}
+
+// NamedWithError 返回指定名称的日志器，返回错误
+func NamedWithError(name string) (*Logger, error) {
+	if name == "" {
+		return nil, fmt.Errorf("logger name cannot be empty")
+	}
+
+	// 确保默认日志器已初始化，命名日志器以它的设置为基础
+	if _, err := getLoggerInternalWithError(); err != nil {
+		return nil, err
+	}
+
+	// 持有读锁，避免在创建过程中基础设置被 SetLoggerSettings 替换
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+
+	namedLoggersMutex.Lock()
+	defer namedLoggersMutex.Unlock()
+
+	if l, ok := namedLoggers[name]; ok {
+		return l, nil
+	}
+
+	var base *Settings
+	if defaultLogger != nil {
+		base = defaultLogger.Settings()
+	}
+	if base == nil {
+		base = NewSettings()
+	}
+
+	l, err := New(base.named(name))
+	if err != nil {
+		return nil, fmt.Errorf("create logger %q failed: %w", name, err)
+	}
+	namedLoggers[name] = l
+	return l, nil
+}
+
+// named 基于当前设置和覆盖项生成命名日志器的设置
+func (s *Settings) named(name string) *Settings {
+	out := *s
+	out.LogNameBase = name
+
+	// 远程输出只由默认日志器持有
+	out.Syslog = nil
+	out.Journald = nil
+	out.Network = nil
+	out.HTTP = nil
+	out.Loki = nil
+	out.OTLP = nil
+	out.GELF = nil
+	out.Forward = nil
+
+	if o := s.Loggers[name]; o != nil {
+		if o.LogNameBase != "" {
+			out.LogNameBase = o.LogNameBase
+		}
+		if o.Level != nil {
+			out.Level = *o.Level
+		}
+		if o.MaxAgeDays > 0 {
+			out.MaxAgeDays = o.MaxAgeDays
+		}
+		if o.MaxSizeMB > 0 {
+			out.MaxSizeMB = o.MaxSizeMB
+		}
+		if o.FormatterType != "" {
+			out.FormatterType = o.FormatterType
+			// 显式指定格式器类型时不再沿用基础设置的自定义格式器
+			out.CustomFormatter = nil
+		}
+		if o.TimestampFormat != "" {
+			out.TimestampFormat = o.TimestampFormat
+		}
+		if o.LogFormat != "" {
+			out.LogFormat = o.LogFormat
+		}
+		if o.TemplateFormat != "" {
+			out.TemplateFormat = o.TemplateFormat
+		}
+		if o.CustomFormatter != nil {
+			out.CustomFormatter = o.CustomFormatter
+		}
+	}
+
+	normalizeSettings(&out)
+	return &out
+}
+
+// closeNamedLoggers 关闭并移除所有命名日志器
+func closeNamedLoggers() error {
+	namedLoggersMutex.Lock()
+	defer namedLoggersMutex.Unlock()
+
+	var closeErrors []error
+	for name, l := range namedLoggers {
+		if err := l.Close(); err != nil {
+			closeErrors = append(closeErrors, fmt.Errorf("failed to close logger %q: %w", name, err))
+		}
+		delete(namedLoggers, name)
+	}
+
+	if len(closeErrors) > 0 {
+		return fmt.Errorf("close named loggers errors: %v", closeErrors)
+	}
+	return nil
+}
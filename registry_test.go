@@ -0,0 +1,231 @@
+package logger
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestNamedLoggers 测试命名日志器按名称懒创建并应用覆盖项
+func TestNamedLoggers(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-named")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	debugLevel := logrus.DebugLevel
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	settings.MaxSizeMB = 1
+	settings.Loggers = map[string]*LoggerOverride{
+		"billing": {
+			LogNameBase:   "billing_service",
+			Level:         &debugLevel,
+			FormatterType: FormatterTypeJSON,
+		},
+	}
+	if err := SetLoggerSettingsWithError(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	billing := Named("billing")
+	if billing != Named("billing") {
+		t.Error("Named should return the same instance for the same name")
+	}
+	if billing.GetLevel() != logrus.DebugLevel {
+		t.Errorf("billing level = %v, want debug", billing.GetLevel())
+	}
+
+	auth := Named("auth")
+	if auth.GetLevel() != logrus.InfoLevel {
+		t.Errorf("auth level = %v, want info", auth.GetLevel())
+	}
+
+	billing.Debug("billing debug message")
+	auth.Debug("auth debug message")
+	auth.Info("auth info message")
+
+	if err := Close(); err != nil {
+		t.Errorf("Close() returned error: %v", err)
+	}
+
+	namedLoggersMutex.Lock()
+	remaining := len(namedLoggers)
+	namedLoggersMutex.Unlock()
+	if remaining != 0 {
+		t.Errorf("Close() should close every named logger, %d remaining", remaining)
+	}
+
+	billingContent, err := os.ReadFile(filepath.Join(root, "billing_service.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(billingContent), `"msg":"billing debug message"`) {
+		t.Errorf("billing log should contain JSON debug entry, got %q", billingContent)
+	}
+
+	authContent, err := os.ReadFile(filepath.Join(root, "auth.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(authContent), "auth debug message") {
+		t.Error("auth log should not contain debug entry")
+	}
+	if !strings.Contains(string(authContent), "auth info message") {
+		t.Error("auth log should contain info entry")
+	}
+}
+
+// TestNamedLoggerInvalidName 测试非法名称返回错误
+func TestNamedLoggerInvalidName(t *testing.T) {
+	if _, err := NamedWithError(""); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if _, err := NamedWithError("a/b"); err == nil {
+		t.Error("expected error for name with invalid characters")
+	}
+}
+
+// TestLoadSettingsFromYAMLLoggers 测试 YAML 中的 loggers 配置
+func TestLoadSettingsFromYAMLLoggers(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-yaml-loggers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "config.yaml")
+	content := `log_name_base: app
+level: info
+loggers:
+  billing:
+    level: debug
+    formatter_type: json
+  scheduler:
+    log_name_base: cron
+    max_size_mb: 5
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettingsFromYAML(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	billing := settings.Loggers["billing"]
+	if billing == nil || billing.Level == nil || *billing.Level != logrus.DebugLevel || billing.FormatterType != FormatterTypeJSON {
+		t.Errorf("unexpected billing override: %+v", billing)
+	}
+	scheduler := settings.Loggers["scheduler"]
+	if scheduler == nil || scheduler.LogNameBase != "cron" || scheduler.MaxSizeMB != 5 || scheduler.Level != nil {
+		t.Errorf("unexpected scheduler override: %+v", scheduler)
+	}
+
+	named := settings.named("scheduler")
+	if named.LogNameBase != "cron" || named.MaxSizeMB != 5 || named.Level != logrus.InfoLevel {
+		t.Errorf("unexpected scheduler settings: %+v", named)
+	}
+}
+
+// TestNamedLoggerSkipsRemoteSinks 测试命名日志器不继承默认日志器的远程输出
+func TestNamedLoggerSkipsRemoteSinks(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-named-sinks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newLineServer(t, ln)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	settings.FormatterType = FormatterTypeJSON
+	settings.Network = &NetworkSettings{Address: ln.Addr().String()}
+	if err := SetLoggerSettingsWithError(settings); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	worker := Named("worker")
+	if n := len(worker.sinkHook.sinks); n != 0 {
+		t.Errorf("named logger should not open remote sinks, got %d", n)
+	}
+
+	worker.Info("from worker")
+	Info("from default")
+	if msg := srv.next(t); msg != "from default" {
+		t.Errorf("received %q, want only entries of the default logger", msg)
+	}
+	if _, err := os.Stat(filepath.Join(root, "spool", "worker")); !os.IsNotExist(err) {
+		t.Errorf("named logger should not create a spool directory, stat err = %v", err)
+	}
+}
+
+// TestNamedLoggerScopedCleanup 测试命名日志器按自己的保留天数只清理自己的文件
+func TestNamedLoggerScopedCleanup(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-named-cleanup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	appOld := filepath.Join(root, "app--202001011200--.log")
+	authOld := filepath.Join(root, "auth-2020-01-01T12-00-00.000.log")
+	billingOld := filepath.Join(root, "billing-2020-01-01T12-00-00.000.log")
+	for _, p := range []string{appOld, authOld, billingOld} {
+		writeAgedFile(t, p, 1, 3*24*time.Hour)
+	}
+
+	s, err := parseSettingsYAML([]byte(`
+log_name_base: app
+days_to_keep: 7
+max_size_mb: 1
+loggers:
+  billing:
+    days_to_keep: 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.LogRootFPath = root
+	if err := SetLoggerSettingsWithError(s); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+
+	Named("auth").Info("auth message")
+	Named("billing").Info("billing message")
+
+	if !exists(appOld) {
+		t.Error("billing retention should not remove files of the default logger")
+	}
+	if !exists(authOld) {
+		t.Error("billing retention should not remove files of other named loggers")
+	}
+	if exists(billingOld) {
+		t.Error("billing should remove its own files older than days_to_keep")
+	}
+}
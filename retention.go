@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// logFile 参与保留策略的日志文件
+type logFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// logFilePattern 返回匹配 name 的日志文件名的正则，包括：
+//   - 时间轮转文件：name--YYYYMMDDHHMM--.log，分层路径下为 name--HHMM--.log
+//   - 组合轮转文件：在时间轮转文件名的 .log 前加序号，如 name--YYYYMMDDHHMM--.1.log
+//   - 大小轮转文件及其备份：name.log、name-2006-01-02T15-04-05.000.log
+//   - 以上文件压缩后的 .gz / .zst
+func logFilePattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(name) +
+		`(--\d{12}--(\.\d+)?|--\d{4}--(\.\d+)?|-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3})?\.log(\.gz|\.zst)?$`)
+}
+
+// collectLogFiles 返回 root 下 name 的日志文件（不含 active）及包括 active 在内的总大小
+func collectLogFiles(root, name, active string) ([]logFile, int64, error) {
+	re := logFilePattern(name)
+	active = filepath.Clean(active)
+
+	var (
+		files []logFile
+		total int64
+	)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 跳过无法访问的目录，继续处理其他文件
+			return nil
+		}
+		if d.IsDir() || !re.MatchString(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		total += info.Size()
+		if filepath.Clean(p) != active {
+			files = append(files, logFile{path: p, size: info.Size(), modTime: info.ModTime()})
+		}
+		return nil
+	})
+	return files, total, err
+}
+
+// removeLogFiles 删除日志文件，分层路径下删除后清理空的日期目录
+func removeLogFiles(root string, files []logFile) {
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			continue
+		}
+		if dir := filepath.Dir(f.path); dir != root && isEmpty(dir) {
+			_ = os.Remove(dir)
+			cleanupEmptyParents(dir, root)
+		}
+	}
+}
+
+// CleanupLogsByQuota 按备份数量和总大小清理 name 的日志文件，包括分层路径 YYYY/MM/DD 下的文件
+// 最旧的文件优先删除：先只保留最新的 maxBackups 个备份，再删除直到总大小不超过 maxTotalSizeMB
+// active 为正在写入的文件，不会被删除，但计入总大小；maxBackups 或 maxTotalSizeMB 为 0 表示不限制
+func CleanupLogsByQuota(root, name string, maxBackups, maxTotalSizeMB int, active string) error {
+	if maxBackups <= 0 && maxTotalSizeMB <= 0 {
+		return nil
+	}
+
+	root = filepath.Clean(root)
+	backups, total, err := collectLogFiles(root, name, active)
+	if err != nil {
+		return err
+	}
+
+	// 按修改时间从旧到新排序，时间相同时按文件名排序
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].modTime.Before(backups[j].modTime)
+		}
+		return backups[i].path < backups[j].path
+	})
+
+	remove := 0
+	if maxBackups > 0 && len(backups) > maxBackups {
+		remove = len(backups) - maxBackups
+	}
+	for _, f := range backups[:remove] {
+		total -= f.size
+	}
+	if maxTotalSizeMB > 0 {
+		limit := int64(maxTotalSizeMB) * 1024 * 1024
+		for remove < len(backups) && total > limit {
+			total -= backups[remove].size
+			remove++
+		}
+	}
+
+	removeLogFiles(root, backups[:remove])
+	return nil
+}
+
+// cleanupLogsByAge 删除 name 的日志文件中修改时间超过 days 天的文件，active 除外
+// 与 CleanupExpiredLogs 不同，只处理 name 的文件，用于同一目录下保留天数不同的多个日志文件
+func cleanupLogsByAge(root, name string, days int, active string) error {
+	if days <= 0 {
+		return nil
+	}
+
+	root = filepath.Clean(root)
+	files, _, err := collectLogFiles(root, name, active)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(days*24) * time.Hour)
+	var expired []logFile
+	for _, f := range files {
+		if f.modTime.Before(cutoff) {
+			expired = append(expired, f)
+		}
+	}
+	removeLogFiles(root, expired)
+	return nil
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeAgedFile 创建指定大小和修改时间的文件
+func writeAgedFile(t *testing.T, path string, size int, age time.Duration) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mt := time.Now().Add(-age)
+	if err := os.Chtimes(path, mt, mt); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// TestCleanupLogsByQuotaMaxBackups 测试只保留最新的 MaxBackups 个备份
+func TestCleanupLogsByQuotaMaxBackups(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-quota-backups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	active := filepath.Join(root, "app.log")
+	writeAgedFile(t, active, 10, 0)
+	writeAgedFile(t, filepath.Join(root, "app-2026-01-01T00-00-00.000.log"), 10, 4*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "app-2026-01-01T01-00-00.000.log.gz"), 10, 3*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "app-2026-01-01T02-00-00.000.log.zst"), 10, 2*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "app-2026-01-01T03-00-00.000.log"), 10, time.Hour)
+	// 其他日志器和无关文件不受影响
+	other := filepath.Join(root, "other-2026-01-01T00-00-00.000.log")
+	writeAgedFile(t, other, 10, 5*time.Hour)
+	notes := filepath.Join(root, "notes.txt")
+	writeAgedFile(t, notes, 10, 5*time.Hour)
+
+	if err := CleanupLogsByQuota(root, "app", 2, 0, active); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"app.log":                             true,
+		"app-2026-01-01T00-00-00.000.log":     false,
+		"app-2026-01-01T01-00-00.000.log.gz":  false,
+		"app-2026-01-01T02-00-00.000.log.zst": true,
+		"app-2026-01-01T03-00-00.000.log":     true,
+		"other-2026-01-01T00-00-00.000.log":   true,
+		"notes.txt":                           true,
+	}
+	for name, kept := range want {
+		if got := exists(filepath.Join(root, name)); got != kept {
+			t.Errorf("%s: exists=%v, want %v", name, got, kept)
+		}
+	}
+}
+
+// TestCleanupLogsByQuotaTotalSize 测试分层路径下按总大小删除最旧的文件并清理空目录
+func TestCleanupLogsByQuotaTotalSize(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-quota-size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	const mb = 1024 * 1024
+	oldest := filepath.Join(root, "2026", "01", "01", "app--2300--.log.gz")
+	older := filepath.Join(root, "2026", "01", "02", "app--0000--.log")
+	newer := filepath.Join(root, "2026", "01", "02", "app--1200--.log")
+	active := filepath.Join(root, "2026", "01", "03", "app--0000--.log")
+	writeAgedFile(t, oldest, mb, 3*time.Hour)
+	writeAgedFile(t, older, mb, 2*time.Hour)
+	writeAgedFile(t, newer, mb, time.Hour)
+	writeAgedFile(t, active, mb, 0)
+
+	// 上限 2MB：活动文件计入总大小但不会被删除
+	if err := CleanupLogsByQuota(root, "app", 0, 2, active); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists(oldest) || exists(older) {
+		t.Error("oldest files should be removed first")
+	}
+	if !exists(newer) || !exists(active) {
+		t.Error("newest backup and active file should be kept")
+	}
+	if exists(filepath.Join(root, "2026", "01", "01")) {
+		t.Error("empty day directory should be removed")
+	}
+}
+
+// TestMaxBackupsSizeRotation 测试大小轮转模式下轮转后执行数量限制
+func TestMaxBackupsSizeRotation(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-quota-rotation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "edge"
+	settings.MaxSizeMB = 1
+	settings.MaxBackups = 1
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.Repeat("x", 1023)
+	for i := 0; i < 3500; i++ {
+		// lumberjack 的备份文件名精确到毫秒，避免同一毫秒内轮转两次
+		if i%1000 == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+		l.Info(line)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if backups := lumberjackBackups(filepath.Join(root, "edge.log")); len(backups) != 1 {
+		t.Errorf("expected 1 backup, got %v", backups)
+	}
+	if !exists(filepath.Join(root, "edge.log")) {
+		t.Error("active file should be kept")
+	}
+}
+
+// TestRetentionValidation 测试保留策略的参数校验和 YAML 解析
+func TestRetentionValidation(t *testing.T) {
+	settings := NewSettings()
+	settings.MaxBackups = -1
+	if err := validateSettings(settings); err == nil {
+		t.Error("negative MaxBackups should be rejected")
+	}
+	settings = NewSettings()
+	settings.MaxTotalSizeMB = -1
+	if err := validateSettings(settings); err == nil {
+		t.Error("negative MaxTotalSizeMB should be rejected")
+	}
+
+	s, err := parseSettingsYAML([]byte("max_backups: 5\nmax_total_size_mb: 200\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.MaxBackups != 5 || s.MaxTotalSizeMB != 200 {
+		t.Errorf("MaxBackups=%d MaxTotalSizeMB=%d, want 5 and 200", s.MaxBackups, s.MaxTotalSizeMB)
+	}
+}
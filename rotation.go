@@ -0,0 +1,419 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	rotatelogs "github.com/lestrrat-go/file-rotatelogs"
+	lumberjack "gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotateLogsHandler 返回 rotatelogs 的轮转事件处理器
+// 开启压缩时在后台压缩上一个文件，压缩完成后由压缩器执行清理；否则直接执行清理
+func rotateLogsHandler(comp *compressor, cleanup func()) rotatelogs.Handler {
+	return rotatelogs.HandlerFunc(func(e rotatelogs.Event) {
+		ev, ok := e.(*rotatelogs.FileRotatedEvent)
+		if !ok || ev.PreviousFile() == "" {
+			return
+		}
+		if comp != nil {
+			comp.compressAsync(ev.PreviousFile())
+			return
+		}
+		cleanup()
+	})
+}
+
+// rotatingFile 按设置中的轮转、压缩和保留策略写入 LogNameBase 对应的日志文件
+// 三种轮转写入器中只有一个非 nil
+type rotatingFile struct {
+	io.Writer
+	rotateLogs *rotatelogs.RotateLogs
+	size       *sizeRotatingWriter
+	timeSize   *timeSizeRotatingWriter
+	activeFile func() string // 正在写入的文件
+	cleanup    func() error  // 按保留策略清理
+	closers    []io.Closer   // 文件写入器和压缩器，按顺序关闭
+}
+
+// openRotatingFile 在 pathRoot 下创建日志文件写入器
+// 按天数清理只处理 LogNameBase 的文件，同一目录下的其他日志器和路由按各自的保留天数清理
+func openRotatingFile(settings *Settings, pathRoot string) (_ *rotatingFile, err error) {
+	f := &rotatingFile{}
+	defer func() {
+		if err != nil {
+			_ = closeWriters(f.closers)
+		}
+	}()
+
+	// 先按天数清理，再按备份数量和总大小清理
+	f.cleanup = func() error {
+		if err := cleanupLogsByAge(pathRoot, settings.LogNameBase, settings.MaxAgeDays, f.activeFile()); err != nil {
+			return err
+		}
+		return CleanupLogsByQuota(pathRoot, settings.LogNameBase, settings.MaxBackups, settings.MaxTotalSizeMB, f.activeFile())
+	}
+	hasQuota := settings.MaxBackups > 0 || settings.MaxTotalSizeMB > 0
+
+	// 轮转后的文件在后台压缩，压缩完成后执行清理
+	var comp *compressor
+	if settings.Compress != CompressNone {
+		comp = newCompressor(settings.Compress, func() {
+			_ = f.cleanup()
+		})
+		// 压缩器在文件写入器之后关闭，等待最后一次轮转的压缩完成
+		defer func() {
+			f.closers = append(f.closers, comp)
+		}()
+	}
+
+	if settings.RotationPolicy == RotationPolicySizeAndTime {
+		// 时间和大小组合轮转模式，由清理函数按天数清理
+		if f.timeSize, err = newTimeSizeRotatingWriter(settings, pathRoot, func(filename string) {
+			if comp != nil {
+				comp.compressAsync(filename)
+				return
+			}
+			_ = f.cleanup()
+		}); err != nil {
+			return nil, err
+		}
+		f.Writer = f.timeSize
+		f.activeFile = f.timeSize.Filename
+		f.closers = append(f.closers, f.timeSize)
+	} else if settings.MaxSizeMB > 0 {
+		// 大小轮转模式，分层路径下跨天后切换到新的日期目录
+		var onRotate func(filename string, closed bool)
+		if comp != nil {
+			// 轮转后压缩备份文件；跨天后上一天的文件不再写入，一并压缩
+			onRotate = func(filename string, closed bool) {
+				comp.compressBackupsAsync(filename)
+				if closed {
+					comp.compressAsync(filename)
+				}
+			}
+		} else if hasQuota {
+			onRotate = func(string, bool) { _ = f.cleanup() }
+		}
+
+		if f.size, err = newSizeRotatingWriter(settings, pathRoot, onRotate); err != nil {
+			return nil, err
+		}
+		if comp != nil {
+			// 上次运行遗留的未压缩备份
+			comp.compressBackupsAsync(f.size.Filename())
+		}
+		f.Writer = f.size
+		f.activeFile = f.size.Filename
+		f.closers = append(f.closers, f.size)
+	} else {
+		// 时间轮转模式
+		var logPattern string
+		if settings.UseHierarchicalPath {
+			// 新格式：按年/月/日分层
+			logPattern = filepath.Join(pathRoot, "%Y", "%m", "%d", settings.LogNameBase+"--%H%M--.log")
+		} else {
+			// 旧格式：扁平结构
+			logPattern = filepath.Join(pathRoot, settings.LogNameBase+"--%Y%m%d%H%M--.log")
+		}
+
+		options := []rotatelogs.Option{
+			rotatelogs.WithMaxAge(settings.MaxAge),
+			rotatelogs.WithRotationTime(settings.RotationTime),
+		}
+		if comp != nil || hasQuota {
+			options = append(options, rotatelogs.WithHandler(rotateLogsHandler(comp, func() { _ = f.cleanup() })))
+		}
+		if f.rotateLogs, err = rotatelogs.New(logPattern, options...); err != nil {
+			return nil, fmt.Errorf("create log file failed: %w", err)
+		}
+		f.Writer = f.rotateLogs
+		f.activeFile = f.rotateLogs.CurrentFileName
+		f.closers = append(f.closers, f.rotateLogs)
+	}
+	return f, nil
+}
+
+// sizeRotatingWriter 基于 lumberjack 的大小轮转写入器
+// lumberjack 没有轮转回调，写入量达到 MaxSize 后检查文件大小，文件变小说明已经轮转，此时调用 onRotate。
+// 开启分层路径时，跨过零点后的第一次写入会关闭当前文件并切换到新的 YYYY/MM/DD 目录
+type sizeRotatingWriter struct {
+	root         string
+	name         string
+	maxSizeMB    int
+	maxAgeDays   int
+	hierarchical bool
+	// onRotate 在轮转后调用，filename 为发生轮转的文件，closed 表示该文件因跨天已不再写入
+	onRotate func(filename string, closed bool)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	day     string
+	current *lumberjack.Logger
+	size    int64 // current 的文件大小，创建时从现有文件读取，之后累加写入的字节数
+	max     int64
+
+	filename atomic.Value // 当前文件路径，供 Filename 无锁读取
+}
+
+func newSizeRotatingWriter(settings *Settings, root string, onRotate func(filename string, closed bool)) (*sizeRotatingWriter, error) {
+	w := &sizeRotatingWriter{
+		root:         root,
+		name:         settings.LogNameBase,
+		maxSizeMB:    settings.MaxSizeMB,
+		maxAgeDays:   settings.MaxAgeDays,
+		hierarchical: settings.UseHierarchicalPath,
+		onRotate:     onRotate,
+		now:          time.Now,
+		max:          int64(settings.MaxSizeMB) * 1024 * 1024,
+	}
+	if err := w.open(w.now()); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// dir 返回 t 时刻日志文件所在的目录
+func (w *sizeRotatingWriter) dir(t time.Time) string {
+	if !w.hierarchical {
+		// 旧格式：扁平结构
+		return w.root
+	}
+	// 新格式：按年/月/日分层
+	return filepath.Join(w.root, t.Format("2006"), t.Format("01"), t.Format("02"))
+}
+
+// open 在 t 对应的目录下打开日志文件，调用方需持有 mu 或尚未发布写入器
+func (w *sizeRotatingWriter) open(t time.Time) error {
+	dir := w.dir(t)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0750); err != nil { // 使用更安全的权限：所有者读写执行，组和其他用户只读
+			return fmt.Errorf("create log dir failed: %w", err)
+		}
+	}
+
+	filename := filepath.Join(dir, w.name+".log")
+	w.current = &lumberjack.Logger{
+		Filename:  filename,
+		MaxSize:   w.maxSizeMB,
+		MaxAge:    w.maxAgeDays,
+		LocalTime: true,
+		Compress:  false,
+	}
+	w.day = t.Format("20060102")
+	w.size = 0
+	if fi, err := os.Stat(filename); err == nil {
+		w.size = fi.Size()
+	}
+	w.filename.Store(filename)
+	return nil
+}
+
+// Filename 返回当前正在写入的文件路径
+func (w *sizeRotatingWriter) Filename() string {
+	return w.filename.Load().(string)
+}
+
+// Write 实现 io.Writer 接口
+func (w *sizeRotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if now := w.now(); w.hierarchical && now.Format("20060102") != w.day {
+		previous := w.current
+		if err := w.open(now); err != nil {
+			return 0, err
+		}
+		_ = previous.Close()
+		w.notify(previous.Filename, true)
+	}
+
+	n, err := w.current.Write(p)
+	w.size += int64(n)
+	// 未达到上限时 lumberjack 不会轮转，达到后读取文件大小：小于写入的总量说明已经轮转到新文件
+	if w.size >= w.max {
+		if fi, statErr := os.Stat(w.current.Filename); statErr == nil {
+			if fi.Size() < w.size {
+				w.notify(w.current.Filename, false)
+			}
+			w.size = fi.Size()
+		}
+	}
+	return n, err
+}
+
+func (w *sizeRotatingWriter) notify(filename string, closed bool) {
+	if w.onRotate != nil {
+		w.onRotate(filename, closed)
+	}
+}
+
+// Close 实现 io.Closer 接口
+func (w *sizeRotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current.Close()
+}
+
+// timeSizeRotatingWriter 同时按时间和大小轮转的写入器
+// 每个 RotationTime 周期开始一个新文件，周期内文件超过 MaxSizeMB 时递增序号另起一个文件：
+//
+//	扁平结构：name--YYYYMMDDHHMM--.log、name--YYYYMMDDHHMM--.1.log、name--YYYYMMDDHHMM--.2.log ...
+//	分层结构：YYYY/MM/DD/name--HHMM--.log、YYYY/MM/DD/name--HHMM--.1.log ...
+type timeSizeRotatingWriter struct {
+	root         string
+	name         string
+	rotationTime time.Duration
+	max          int64 // 0 表示不按大小轮转
+	hierarchical bool
+	// onRotate 在轮转后调用，filename 为不再写入的文件
+	onRotate func(filename string)
+	now      func() time.Time
+
+	mu     sync.Mutex
+	period time.Time
+	seq    int
+	file   *os.File
+	size   int64
+	closed bool
+
+	filename atomic.Value // 当前文件路径，供 Filename 无锁读取
+}
+
+func newTimeSizeRotatingWriter(settings *Settings, root string, onRotate func(filename string)) (*timeSizeRotatingWriter, error) {
+	w := &timeSizeRotatingWriter{
+		root:         root,
+		name:         settings.LogNameBase,
+		rotationTime: settings.RotationTime,
+		max:          int64(settings.MaxSizeMB) * 1024 * 1024,
+		hierarchical: settings.UseHierarchicalPath,
+		onRotate:     onRotate,
+		now:          time.Now,
+	}
+	if err := w.openPeriod(w.now().Truncate(w.rotationTime)); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// path 返回周期 period 中序号为 seq 的文件路径
+func (w *timeSizeRotatingWriter) path(period time.Time, seq int) string {
+	suffix := ".log"
+	if seq > 0 {
+		suffix = "." + strconv.Itoa(seq) + ".log"
+	}
+	if !w.hierarchical {
+		// 旧格式：扁平结构
+		return filepath.Join(w.root, w.name+"--"+period.Format("200601021504")+"--"+suffix)
+	}
+	// 新格式：按年/月/日分层
+	return filepath.Join(w.root, period.Format("2006"), period.Format("01"), period.Format("02"),
+		w.name+"--"+period.Format("1504")+"--"+suffix)
+}
+
+// openPeriod 打开周期 period 的文件，沿用上次运行留下的最大序号，该文件已满时使用下一个序号
+func (w *timeSizeRotatingWriter) openPeriod(period time.Time) error {
+	seq := 0
+	for {
+		if _, err := os.Stat(w.path(period, seq+1)); err != nil {
+			break
+		}
+		seq++
+	}
+	if fi, err := os.Stat(w.path(period, seq)); err == nil && w.max > 0 && fi.Size() >= w.max {
+		seq++
+	}
+	w.period = period
+	return w.open(seq)
+}
+
+// open 打开当前周期中序号为 seq 的文件
+func (w *timeSizeRotatingWriter) open(seq int) error {
+	filename := w.path(w.period, seq)
+	if err := os.MkdirAll(filepath.Dir(filename), 0750); err != nil { // 使用更安全的权限：所有者读写执行，组和其他用户只读
+		return fmt.Errorf("create log dir failed: %w", err)
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("open log file failed: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.seq = seq
+	w.size = fi.Size()
+	w.filename.Store(filename)
+	return nil
+}
+
+// rotate 关闭当前文件并通知轮转
+func (w *timeSizeRotatingWriter) rotate() {
+	if w.file == nil {
+		return
+	}
+	previous := w.file.Name()
+	_ = w.file.Close()
+	w.file = nil
+	if w.onRotate != nil {
+		w.onRotate(previous)
+	}
+}
+
+// Filename 返回当前正在写入的文件路径
+func (w *timeSizeRotatingWriter) Filename() string {
+	return w.filename.Load().(string)
+}
+
+// Write 实现 io.Writer 接口
+func (w *timeSizeRotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	period := w.now().Truncate(w.rotationTime)
+	switch {
+	case w.file == nil || !period.Equal(w.period):
+		// 新的周期（或上次打开文件失败）
+		w.rotate()
+		if err := w.openPeriod(period); err != nil {
+			return 0, err
+		}
+	case w.max > 0 && w.size > 0 && w.size+int64(len(p)) > w.max:
+		// 周期内超过大小，递增序号
+		w.rotate()
+		if err := w.open(w.seq + 1); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close 实现 io.Closer 接口，关闭后的写入返回 os.ErrClosed
+func (w *timeSizeRotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.closed = true
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
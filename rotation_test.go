@@ -0,0 +1,309 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSizeRotatingWriterFollowsDay 测试分层路径下大小轮转跨天后切换到新的日期目录
+func TestSizeRotatingWriterFollowsDay(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-size-day")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogNameBase = "edge"
+	settings.MaxSizeMB = 1
+	settings.UseHierarchicalPath = true
+
+	type rotation struct {
+		filename string
+		closed   bool
+	}
+	var rotations []rotation
+	w, err := newSizeRotatingWriter(settings, root, func(filename string, closed bool) {
+		rotations = append(rotations, rotation{filename, closed})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	day1 := time.Date(2026, 1, 1, 23, 59, 59, 0, time.Local)
+	w.now = func() time.Time { return day1 }
+	if _, err := w.Write([]byte("day one\n")); err != nil {
+		t.Fatal(err)
+	}
+	first := w.Filename()
+
+	day2 := day1.Add(2 * time.Second)
+	w.now = func() time.Time { return day2 }
+	if _, err := w.Write([]byte("day two\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(root, "2026", "01", "02", "edge.log")
+	if w.Filename() != want {
+		t.Errorf("Filename() = %s, want %s", w.Filename(), want)
+	}
+	b, err := os.ReadFile(want)
+	if err != nil || string(b) != "day two\n" {
+		t.Errorf("new day file content = %q, err=%v", b, err)
+	}
+	b, err = os.ReadFile(filepath.Join(root, "2026", "01", "01", "edge.log"))
+	if err != nil || string(b) != "day one\n" {
+		t.Errorf("previous day file content = %q, err=%v", b, err)
+	}
+
+	// 写入器在真实的当前日期创建，第一次写入时也会切换一次
+	last := rotations[len(rotations)-1]
+	if len(rotations) != 2 || last.filename != first || !last.closed {
+		t.Errorf("rotations = %+v, want the previous day file %s marked closed", rotations, first)
+	}
+}
+
+// TestSizeRotatingWriterFlat 测试扁平结构下跨天不切换文件
+func TestSizeRotatingWriterFlat(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-size-flat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogNameBase = "edge"
+	settings.MaxSizeMB = 1
+
+	w, err := newSizeRotatingWriter(settings, root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.now = func() time.Time { return time.Now().Add(48 * time.Hour) }
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "edge.log"); w.Filename() != want {
+		t.Errorf("Filename() = %s, want %s", w.Filename(), want)
+	}
+}
+
+// TestSizeRotatingWriterMatchesLumberjack 测试检测到的轮转与 lumberjack 实际的轮转一致，包括从现有文件继续写入和刚好写满的情况
+func TestSizeRotatingWriterMatchesLumberjack(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-size-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogNameBase = "edge"
+	settings.MaxSizeMB = 1
+	const max = 1024 * 1024
+
+	filename := filepath.Join(root, "edge.log")
+	if err := os.WriteFile(filename, make([]byte, max-10), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rotations := 0
+	w, err := newSizeRotatingWriter(settings, root, func(string, bool) { rotations++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.size != max-10 {
+		t.Errorf("size = %d, want %d from the existing file", w.size, max-10)
+	}
+
+	backups := func() int {
+		matches, _ := filepath.Glob(filepath.Join(root, "edge-*.log"))
+		return len(matches)
+	}
+
+	// 第一次写入：现有大小加写入长度刚好等于上限时 lumberjack 会轮转
+	if _, err := w.Write(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if rotations != 1 || backups() != 1 {
+		t.Fatalf("first write: rotations = %d, backups = %d, want 1", rotations, backups())
+	}
+
+	// 之后写入刚好写满上限时不轮转，超过上限才轮转
+	if _, err := w.Write(make([]byte, max-10)); err != nil {
+		t.Fatal(err)
+	}
+	if rotations != 1 || backups() != 1 {
+		t.Fatalf("filling the file: rotations = %d, backups = %d, want 1", rotations, backups())
+	}
+	// lumberjack 备份文件名精确到毫秒，避免两次轮转使用同一个文件名
+	time.Sleep(2 * time.Millisecond)
+	if _, err := w.Write([]byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	if rotations != 2 || backups() != 2 {
+		t.Errorf("overflow: rotations = %d, backups = %d, want 2", rotations, backups())
+	}
+}
+
+// TestTimeSizeRotatingWriter 测试组合轮转按大小递增序号、按时间开始新周期
+func TestTimeSizeRotatingWriter(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-time-size")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogNameBase = "app"
+	settings.RotationTime = time.Hour
+	settings.MaxSizeMB = 1
+
+	var rotated []string
+	w, err := newTimeSizeRotatingWriter(settings, root, func(filename string) {
+		rotated = append(rotated, filepath.Base(filename))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 写入器创建时即打开当前周期的文件
+	initial := w.Filename()
+	if _, err := os.Stat(initial); err != nil {
+		t.Fatalf("current period file should exist after creation: %v", err)
+	}
+
+	now := time.Date(2026, 1, 1, 12, 10, 0, 0, time.Local)
+	w.now = func() time.Time { return now }
+
+	chunk := make([]byte, 600*1024)
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := filepath.Base(w.Filename()); got != "app--202601011200--.2.log" {
+		t.Errorf("Filename() = %s, want app--202601011200--.2.log", got)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := w.Write([]byte("next period\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := filepath.Base(w.Filename()); got != "app--202601011300--.log" {
+		t.Errorf("Filename() = %s, want app--202601011300--.log", got)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 写入器在真实的当前时间创建，第一次写入时也会切换一次
+	want := []string{filepath.Base(initial), "app--202601011200--.log", "app--202601011200--.1.log", "app--202601011200--.2.log"}
+	if len(rotated) != len(want) {
+		t.Fatalf("rotated = %v, want %v", rotated, want)
+	}
+	for i := range want {
+		if rotated[i] != want[i] {
+			t.Errorf("rotated[%d] = %s, want %s", i, rotated[i], want[i])
+		}
+	}
+
+	if _, err := w.Write([]byte("after close\n")); err != os.ErrClosed {
+		t.Errorf("Write() after Close = %v, want os.ErrClosed", err)
+	}
+
+	// 重新打开同一周期时沿用最大序号
+	w, err = newTimeSizeRotatingWriter(settings, root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.now = func() time.Time { return now.Add(-time.Hour) }
+	if _, err := w.Write([]byte("restart\n")); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if got := filepath.Base(w.Filename()); got != "app--202601011200--.2.log" {
+		t.Errorf("after restart Filename() = %s, want app--202601011200--.2.log", got)
+	}
+}
+
+// TestTimeSizeRotatingWriterHierarchical 测试组合轮转的分层路径文件名
+func TestTimeSizeRotatingWriterHierarchical(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-time-size-hier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogNameBase = "app"
+	settings.RotationTime = time.Hour
+	settings.UseHierarchicalPath = true
+
+	w, err := newTimeSizeRotatingWriter(settings, root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.now = func() time.Time { return time.Date(2026, 1, 2, 8, 30, 0, 0, time.Local) }
+	if _, err := w.Write([]byte("line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := filepath.Join(root, "2026", "01", "02", "app--0800--.log"); w.Filename() != want {
+		t.Errorf("Filename() = %s, want %s", w.Filename(), want)
+	}
+}
+
+// TestRotationPolicySizeAndTime 测试通过设置启用组合轮转，并能清理组合轮转的文件
+func TestRotationPolicySizeAndTime(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-policy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	expired := filepath.Join(root, "app--202001011200--.3.log.gz")
+	writeAgedFile(t, expired, 1, 30*24*time.Hour)
+
+	s, err := parseSettingsYAML([]byte("rotation_policy: size_and_time\nmax_size_mb: 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RotationPolicy != RotationPolicySizeAndTime {
+		t.Fatalf("RotationPolicy = %q, want size_and_time", s.RotationPolicy)
+	}
+	s.LogRootFPath = root
+	s.LogNameBase = "app"
+
+	l, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 创建后、第一次写入前即可得到当前文件路径
+	if _, err := os.Stat(l.LogLinkFileFPath()); err != nil {
+		t.Errorf("LogLinkFileFPath() = %q should exist right after New: %v", l.LogLinkFileFPath(), err)
+	}
+	l.Info("combined rotation")
+	name := l.CurrentFileName()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(filepath.Base(name), "app--") || !strings.HasSuffix(name, "--.log") {
+		t.Errorf("unexpected file name %s", name)
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Error("expired combined rotation file should be removed")
+	}
+
+	s.RotationPolicy = "weekly"
+	if err := validateSettings(s); err == nil {
+		t.Error("unknown RotationPolicy should be rejected")
+	}
+}
@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelRoute 按级别把条目另外写入单独的日志文件，文件名以 <LogNameBase>.<Name> 为基础，
+// 如大小轮转时为 app.error.log，时间轮转时为 app.error--YYYYMMDDHHMM--.log
+// 轮转与保留字段的零值表示沿用主日志文件的设置
+type LevelRoute struct {
+	Name   string         // 文件名后缀，如 "error"、"debug"
+	Level  logrus.Level   // 写入此级别及更严重的条目，如 WarnLevel 包括 Warn、Error、Fatal、Panic；零值 PanicLevel 视为未设置
+	Levels []logrus.Level // 设置后只写入列出的级别，忽略 Level；只路由 Panic 条目时使用 []logrus.Level{logrus.PanicLevel}
+
+	RotationTime   time.Duration // 日志轮转时间
+	MaxAgeDays     int           // 日志最大保存天数
+	MaxSizeMB      int           // 文件大小限制(MB)
+	MaxBackups     int           // 最多保留的轮转文件数量
+	MaxTotalSizeMB int           // 日志文件总大小上限(MB)
+	RotationPolicy string        // 轮转策略
+	Compress       string        // 轮转后的压缩方式
+}
+
+// validate 验证路由配置
+func (r *LevelRoute) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("route name cannot be empty")
+	}
+	if strings.ContainsAny(r.Name, `/\:*?"<>|`) {
+		return fmt.Errorf("route name %q contains invalid characters", r.Name)
+	}
+	// Level 的零值是 PanicLevel，无法区分未设置，因此要求显式设置 Level 或 Levels
+	if r.Level == logrus.PanicLevel && len(r.Levels) == 0 {
+		return fmt.Errorf("route %q must set Level or Levels", r.Name)
+	}
+	for _, level := range append([]logrus.Level{r.Level}, r.Levels...) {
+		if level > logrus.TraceLevel {
+			return fmt.Errorf("route %q has invalid level %d", r.Name, level)
+		}
+	}
+	if r.RotationTime < 0 || r.MaxAgeDays < 0 || r.MaxSizeMB < 0 || r.MaxBackups < 0 || r.MaxTotalSizeMB < 0 {
+		return fmt.Errorf("route %q limits cannot be negative", r.Name)
+	}
+	if r.RotationTime > 0 && r.RotationTime < time.Minute {
+		return fmt.Errorf("route %q RotationTime too small (min: 1 minute)", r.Name)
+	}
+	switch r.RotationPolicy {
+	case RotationPolicyDefault, RotationPolicySizeAndTime:
+	default:
+		return fmt.Errorf("route %q has unknown RotationPolicy: %s", r.Name, r.RotationPolicy)
+	}
+	switch r.Compress {
+	case CompressNone, CompressGzip, CompressZstd:
+	default:
+		return fmt.Errorf("route %q has unknown Compress: %s", r.Name, r.Compress)
+	}
+	return nil
+}
+
+// mostVerbose 返回路由接收的最详细级别
+func (r *LevelRoute) mostVerbose() logrus.Level {
+	if len(r.Levels) == 0 {
+		return r.Level
+	}
+	level := r.Levels[0]
+	for _, l := range r.Levels[1:] {
+		if l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// route 基于当前设置和路由的覆盖项生成路由文件的设置
+func (s *Settings) route(r *LevelRoute) *Settings {
+	out := *s
+	out.LogNameBase = s.LogNameBase + "." + r.Name
+	out.Routes = nil
+
+	if r.RotationTime > 0 {
+		out.RotationTime = r.RotationTime
+	}
+	if r.MaxAgeDays > 0 {
+		out.MaxAgeDays = r.MaxAgeDays
+	}
+	if r.MaxSizeMB > 0 {
+		out.MaxSizeMB = r.MaxSizeMB
+	}
+	if r.MaxBackups > 0 {
+		out.MaxBackups = r.MaxBackups
+	}
+	if r.MaxTotalSizeMB > 0 {
+		out.MaxTotalSizeMB = r.MaxTotalSizeMB
+	}
+	if r.RotationPolicy != "" {
+		out.RotationPolicy = r.RotationPolicy
+	}
+	if r.Compress != "" {
+		out.Compress = r.Compress
+	}
+
+	normalizeSettings(&out)
+	return &out
+}
+
+// routeSink 使用自己的格式器将匹配级别的条目写入路由文件或控制台
+// 与其他输出不同，路由按自己的级别接收条目，不受基础级别限制，只受匹配到的模块级别规则限制
+type routeSink struct {
+	levels     [logrus.TraceLevel + 1]bool
+	followBase bool // 为 true 时忽略 levels，与文件使用相同的级别过滤
+	formatter  logrus.Formatter
+	out        io.Writer
+	file       *rotatingFile // 路由文件，写入控制台时为 nil
+	async      *asyncWriter  // 异步模式下写入 out 的队列，否则为 nil
+	closers    []io.Closer
+}
+
+func newRouteSink(r *LevelRoute, settings *Settings, pathRoot string) (*routeSink, error) {
+	rs := settings.route(r)
+	file, err := openRotatingFile(rs, pathRoot)
+	if err != nil {
+		return nil, fmt.Errorf("create route %q failed: %w", r.Name, err)
+	}
+
+	w := &routeSink{
+		formatter: (&FormatterFactory{}).CreateFormatter(settings),
+		out:       file,
+		file:      file,
+		closers:   file.closers,
+	}
+	if len(r.Levels) > 0 {
+		for _, level := range r.Levels {
+			w.levels[level] = true
+		}
+	} else {
+		for level := logrus.PanicLevel; level <= r.Level; level++ {
+			w.levels[level] = true
+		}
+	}
+	if settings.Async {
+		w.async = newAsyncWriter(file, settings)
+		w.closers = append([]io.Closer{w.async}, w.closers...)
+	}
+	return w, nil
+}
+
+// accepts 判断条目是否写入该路由
+func (w *routeSink) accepts(entry *logrus.Entry, filter *moduleLevelFilter) bool {
+	if w.followBase {
+		return filter == nil || filter.allows(entry)
+	}
+	if entry.Level > logrus.TraceLevel || !w.levels[entry.Level] {
+		return false
+	}
+	return filter == nil || filter.ruleAllows(entry)
+}
+
+// Send 实现 sink 接口
+func (w *routeSink) Send(entry *logrus.Entry) error {
+	b, err := w.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	if w.async != nil {
+		// 与 asyncLevelFormatter 相同，在 logrus 的互斥锁内记录级别供丢弃策略使用
+		w.async.level = entry.Level
+		_, err = w.async.Write(b)
+		return err
+	}
+	_, err = w.out.Write(b)
+	return err
+}
+
+// Flush 等待异步队列中已有的条目写入
+func (w *routeSink) Flush(ctx context.Context) error {
+	if w.async == nil {
+		return nil
+	}
+	return w.async.Flush(ctx)
+}
+
+// Close 实现 io.Closer 接口
+func (w *routeSink) Close() error {
+	return closeWriters(w.closers)
+}
+
+// newRouteSinks 根据设置创建所有路由，出错时关闭已创建的路由
+func newRouteSinks(settings *Settings, pathRoot string) (routes []*routeSink, err error) {
+	defer func() {
+		if err != nil {
+			for _, r := range routes {
+				_ = r.Close()
+			}
+			routes = nil
+		}
+	}()
+
+	for i := range settings.Routes {
+		r, err := newRouteSink(&settings.Routes[i], settings, pathRoot)
+		if err != nil {
+			return routes, err
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// readLogFiles 返回 root 下 name 的所有日志文件内容
+func readLogFiles(t *testing.T, root, name string) string {
+	files, _, err := collectLogFiles(root, name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	for _, f := range files {
+		b, err := os.ReadFile(f.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sb.Write(b)
+	}
+	return sb.String()
+}
+
+func checkContains(t *testing.T, what, output string, want, unwanted []string) {
+	t.Helper()
+	for _, s := range want {
+		if !strings.Contains(output, s) {
+			t.Errorf("%s should contain %q, got %q", what, s, output)
+		}
+	}
+	for _, s := range unwanted {
+		if strings.Contains(output, s) {
+			t.Errorf("%s should not contain %q", what, s)
+		}
+	}
+}
+
+// TestRoutes 测试按级别把条目另外写入单独的文件
+func TestRoutes(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-routes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	settings.MaxSizeMB = 10
+	settings.Level = logrus.InfoLevel
+	settings.ModuleLevels = map[string]logrus.Level{"billing": logrus.ErrorLevel}
+	settings.Routes = []LevelRoute{
+		{Name: "error", Level: logrus.WarnLevel},
+		{Name: "debug", Levels: []logrus.Level{logrus.DebugLevel}, MaxAgeDays: 1},
+	}
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.GetLevel(); got != logrus.InfoLevel {
+		t.Errorf("GetLevel() = %v, want info", got)
+	}
+	l.Debug("debug line")
+	l.Info("info line")
+	l.Warn("warn line")
+	l.Error("error line")
+	l.WithField(ModuleFieldKey, "billing").Warn("billing warn")
+	l.WithField(ModuleFieldKey, "billing").Error("billing error")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkContains(t, "main file", readLogFiles(t, root, "app"),
+		[]string{"info line", "warn line", "error line", "billing error"},
+		[]string{"debug line", "billing warn"})
+	checkContains(t, "error route", readLogFiles(t, root, "app.error"),
+		[]string{"warn line", "error line", "billing error"},
+		[]string{"debug line", "info line", "billing warn"})
+	checkContains(t, "debug route", readLogFiles(t, root, "app.debug"),
+		[]string{"debug line"},
+		[]string{"info line", "warn line", "error line"})
+}
+
+// TestRoutesAsync 测试异步模式下路由文件在关闭时写完
+func TestRoutesAsync(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-routes-async")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	settings.Async = true
+	settings.Routes = []LevelRoute{{Name: "error", Level: logrus.ErrorLevel}}
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		l.Errorf("async error %d", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Count(readLogFiles(t, root, "app.error"), "async error"); got != 100 {
+		t.Errorf("error route has %d lines, want 100", got)
+	}
+}
+
+// TestCleanupLogsByAge 测试按天数清理只处理指定名称的文件
+func TestCleanupLogsByAge(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-routes-age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	active := filepath.Join(root, "app.debug.log")
+	writeAgedFile(t, active, 10, 72*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "app.debug-2026-01-01T00-00-00.000.log"), 10, 72*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "2026", "01", "01", "app.debug--0000--.log.gz"), 10, 72*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "app.debug-2026-01-03T00-00-00.000.log"), 10, time.Hour)
+	// 保留天数更长的主文件和错误文件不受影响
+	writeAgedFile(t, filepath.Join(root, "app-2026-01-01T00-00-00.000.log"), 10, 72*time.Hour)
+	writeAgedFile(t, filepath.Join(root, "app.error-2026-01-01T00-00-00.000.log"), 10, 72*time.Hour)
+
+	if err := cleanupLogsByAge(root, "app.debug", 1, active); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"app.debug.log":                         true,
+		"app.debug-2026-01-01T00-00-00.000.log": false,
+		"2026":                                  false,
+		"app.debug-2026-01-03T00-00-00.000.log": true,
+		"app-2026-01-01T00-00-00.000.log":       true,
+		"app.error-2026-01-01T00-00-00.000.log": true,
+	}
+	for name, kept := range want {
+		if got := exists(filepath.Join(root, name)); got != kept {
+			t.Errorf("%s: exists=%v, want %v", name, got, kept)
+		}
+	}
+}
+
+// TestRoutesValidation 测试路由的参数校验和 YAML 解析
+func TestRoutesValidation(t *testing.T) {
+	invalid := [][]LevelRoute{
+		{{Level: logrus.WarnLevel}},
+		{{Name: "a/b", Level: logrus.WarnLevel}},
+		{{Name: "x"}},
+		{{Name: "x", Level: logrus.WarnLevel, MaxAgeDays: -1}},
+		{{Name: "x", Level: logrus.WarnLevel, RotationTime: time.Second}},
+		{{Name: "x", Level: logrus.WarnLevel, Compress: "lz4"}},
+		{{Name: "x", Levels: []logrus.Level{logrus.Level(9)}}},
+		{{Name: "x", Level: logrus.WarnLevel}, {Name: "x", Level: logrus.ErrorLevel}},
+	}
+	for _, routes := range invalid {
+		settings := NewSettings()
+		settings.Routes = routes
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("routes %+v should be invalid", routes)
+		}
+	}
+
+	s, err := parseSettingsYAML([]byte(`
+routes:
+  - name: error
+    level: warn
+    max_size_mb: 20
+    compress: gzip
+  - name: debug
+    levels: [debug, trace]
+    days_to_keep: 1
+    rotation_time: 1h
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Routes) != 2 {
+		t.Fatalf("got %d routes, want 2", len(s.Routes))
+	}
+	if r := s.Routes[0]; r.Name != "error" || r.Level != logrus.WarnLevel || r.MaxSizeMB != 20 || r.Compress != CompressGzip {
+		t.Errorf("unexpected error route: %+v", r)
+	}
+	r := s.Routes[1]
+	if r.Name != "debug" || len(r.Levels) != 2 || r.Levels[1] != logrus.TraceLevel || r.MaxAgeDays != 1 || r.RotationTime != time.Hour {
+		t.Errorf("unexpected debug route: %+v", r)
+	}
+	if got := r.mostVerbose(); got != logrus.TraceLevel {
+		t.Errorf("mostVerbose() = %v, want trace", got)
+	}
+	if err := validateSettings(s); err != nil {
+		t.Errorf("parsed routes should be valid: %v", err)
+	}
+
+	// 未设置 level 或 levels 的路由无效，只路由 Panic 条目需要显式列出
+	s, err = parseSettingsYAML([]byte("routes:\n  - name: error\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSettings(s); err == nil {
+		t.Error("route without level or levels should be invalid")
+	}
+	s, err = parseSettingsYAML([]byte("routes:\n  - name: panic\n    levels: [panic]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSettings(s); err != nil {
+		t.Errorf("route with levels [panic] should be valid: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sink 文件之外的日志输出，例如 syslog、journald 和网络收集端
+// Send 在 logrus 的互斥锁内调用，实现不能在返回后继续持有 entry，需要缓冲的输出应先序列化
+type sink interface {
+	Send(entry *logrus.Entry) error
+	io.Closer
+}
+
+// flusher 可以立即发送缓冲中条目的输出
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// statsSink 提供发送统计的批量输出
+type statsSink interface {
+	sinkName() string
+	deliveryStats() DeliveryStats
+}
+
+// sinkHook 将条目分发给日志器的所有输出
+// 日志器创建时注册一次，Reload 通过 set 原地替换输出，用户添加的 Hook 不受影响
+type sinkHook struct {
+	mu     sync.RWMutex // Fire 持有读锁，set 等待正在进行的分发完成后再替换
+	sinks  []sink
+	routes []*routeSink       // 按级别路由的文件，按各自的级别接收条目
+	filter *moduleLevelFilter // 与文件输出使用相同的模块级别过滤
+}
+
+// Levels 实现 logrus.Hook 接口
+func (h *sinkHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 实现 logrus.Hook 接口
+func (h *sinkHook) Fire(entry *logrus.Entry) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var errs []string
+	for _, r := range h.routes {
+		if !r.accepts(entry, h.filter) {
+			continue
+		}
+		if err := r.Send(entry); err != nil {
+			errs = append(errs, fmt.Sprintf("%T: %v", r, err))
+		}
+	}
+
+	if len(h.sinks) == 0 || (h.filter != nil && !h.filter.allows(entry)) {
+		return combineSinkErrors(errs)
+	}
+	for _, s := range h.sinks {
+		if err := s.Send(entry); err != nil {
+			errs = append(errs, fmt.Sprintf("%T: %v", s, err))
+		}
+	}
+	return combineSinkErrors(errs)
+}
+
+func combineSinkErrors(errs []string) error {
+	if len(errs) > 0 {
+		return fmt.Errorf("sink errors: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// flush 立即发送各输出缓冲中的条目
+func (h *sinkHook) flush(ctx context.Context) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, r := range h.routes {
+		if err := r.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	for _, s := range h.sinks {
+		if f, ok := s.(flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setRoutes 替换路由和模块级别过滤
+func (h *sinkHook) setRoutes(routes []*routeSink, filter *moduleLevelFilter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.routes = routes
+	h.filter = filter
+}
+
+// reopen 暂停分发，关闭当前输出后按新设置创建输出
+// Network、GELF 和 forward 输出的磁盘缓冲目录由日志目录和名称决定，新旧输出同时打开会争用同一目录，
+// 导致条目重复或丢失，因此旧输出先排空队列并关闭，再创建新输出。创建失败时按 previous 恢复原来的输出
+// 返回关闭旧输出时的错误和创建新输出时的错误，恢复也失败时错误中包含恢复的错误，此时日志器没有远程输出
+func (h *sinkHook) reopen(settings, previous *Settings) (closeErr, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	closeErr = closeSinks(h.sinks)
+	h.sinks = nil
+	if h.sinks, err = newSinks(settings); err != nil {
+		if previous != nil {
+			var restoreErr error
+			if h.sinks, restoreErr = newSinks(previous); restoreErr != nil {
+				return closeErr, fmt.Errorf("%w; restore previous sinks failed: %v", err, restoreErr)
+			}
+		}
+		return closeErr, err
+	}
+	return closeErr, nil
+}
+
+// close 关闭所有输出，重复调用是安全的
+func (h *sinkHook) close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	err := closeSinks(h.sinks)
+	h.sinks = nil
+	return err
+}
+
+// closeSinks 关闭输出，返回遇到的错误
+func closeSinks(sinks []sink) error {
+	var closeErrors []error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil {
+			closeErrors = append(closeErrors, err)
+		}
+	}
+	if len(closeErrors) > 0 {
+		return fmt.Errorf("close sinks errors: %v", closeErrors)
+	}
+	return nil
+}
+
+// newSinks 根据设置创建文件之外的输出，出错时关闭已创建的输出
+func newSinks(settings *Settings) (sinks []sink, err error) {
+	defer func() {
+		if err != nil {
+			for _, s := range sinks {
+				_ = s.Close()
+			}
+			sinks = nil
+		}
+	}()
+
+	if settings.Syslog != nil {
+		s, err := newSyslogSink(settings.Syslog, settings.LogNameBase)
+		if err != nil {
+			return sinks, fmt.Errorf("create syslog sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.Journald != nil {
+		s, err := newJournaldSink(settings.Journald, settings.LogNameBase)
+		if err != nil {
+			return sinks, fmt.Errorf("create journald sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.Network != nil {
+		s, err := newNetworkSink(settings.Network, settings)
+		if err != nil {
+			return sinks, fmt.Errorf("create network sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.HTTP != nil {
+		s, err := newHTTPSink(settings.HTTP, settings)
+		if err != nil {
+			return sinks, fmt.Errorf("create http sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.Loki != nil {
+		s, err := newLokiSink(settings.Loki, settings)
+		if err != nil {
+			return sinks, fmt.Errorf("create loki sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.OTLP != nil {
+		s, err := newOTLPSink(settings.OTLP, settings)
+		if err != nil {
+			return sinks, fmt.Errorf("create otlp sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.GELF != nil {
+		s, err := newGELFSink(settings.GELF, settings)
+		if err != nil {
+			return sinks, fmt.Errorf("create gelf sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	if settings.Forward != nil {
+		s, err := newForwardSink(settings.Forward, settings)
+		if err != nil {
+			return sinks, fmt.Errorf("create forward sink failed: %w", err)
+		}
+		sinks = append(sinks, s)
+	}
+
+	return sinks, nil
+}
+
+// DeliveryStats 返回批量输出和 syslog 的发送统计，键为输出名称，如 "http"、"syslog"
+func (l *Logger) DeliveryStats() map[string]DeliveryStats {
+	l.mu.RLock()
+	h := l.sinkHook
+	l.mu.RUnlock()
+
+	stats := make(map[string]DeliveryStats)
+	if h == nil {
+		return stats
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, s := range h.sinks {
+		if d, ok := s.(statsSink); ok {
+			stats[d.sinkName()] = d.deliveryStats()
+		}
+	}
+	return stats
+}
+
+// NetworkStats 返回网络输出的发送统计，未配置网络输出时返回零值
+func (l *Logger) NetworkStats() NetworkStats {
+	l.mu.RLock()
+	h := l.sinkHook
+	l.mu.RUnlock()
+	if h == nil {
+		return NetworkStats{}
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, s := range h.sinks {
+		if n, ok := s.(*networkSink); ok {
+			return n.Stats()
+		}
+	}
+	return NetworkStats{}
+}
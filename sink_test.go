@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"os"
+	"testing"
+)
+
+// newSinkTestLogger 创建写入临时目录的日志器，configure 用于设置各输出的配置，测试结束时关闭
+func newSinkTestLogger(t *testing.T, configure func(*Settings)) *Logger {
+	root, err := os.MkdirTemp("", "logger-ut-sink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	configure(settings)
+
+	l, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
@@ -0,0 +1,262 @@
+package logger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	spoolExt             = ".spool"
+	spoolSegmentBytesMax = 4 * 1024 * 1024 // 单个段文件的最大字节数
+)
+
+// spoolSegment 磁盘缓冲的段文件
+type spoolSegment struct {
+	seq     uint64
+	size    int64
+	records int // 段中的记录数
+}
+
+// diskSpool 按写入顺序保存待发送记录的磁盘缓冲，进程重启后继续从最旧的记录开始发送
+// 记录保存在 <dir>/<序号>.spool 段文件中，每条记录为 4 字节大端长度加内容。
+// 超过 maxBytes 时删除最旧的段文件。diskSpool 不是并发安全的，由所属输出的后台协程独占使用
+type diskSpool struct {
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+
+	segments []spoolSegment // 按序号排序，最后一个为正在写入的段
+	writer   *os.File       // 正在写入的段，重启后总是新建，避免在不完整的记录后追加
+	reader   *os.File       // 正在读取的段（segments[0]）
+	offset   int64          // segments[0] 中已发送的字节数
+	consumed int            // segments[0] 中已发送的记录数
+	pending  []byte         // peek 读出但尚未 advance 的记录
+}
+
+func openDiskSpool(dir string, maxBytes int64) (*diskSpool, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("create spool dir failed: %w", err)
+	}
+
+	s := &diskSpool{dir: dir, maxBytes: maxBytes, segmentBytes: spoolSegmentBytesMax}
+	if maxBytes > 0 && maxBytes/4 < s.segmentBytes {
+		s.segmentBytes = maxBytes / 4
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), spoolExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), spoolExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		if info.Size() == 0 {
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		s.segments = append(s.segments, spoolSegment{
+			seq:     seq,
+			size:    info.Size(),
+			records: countSpoolRecords(filepath.Join(dir, f.Name())),
+		})
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+	return s, nil
+}
+
+func (s *diskSpool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolExt))
+}
+
+// empty 是否没有待发送的记录
+func (s *diskSpool) empty() bool {
+	return s.pending == nil && (len(s.segments) == 0 || (len(s.segments) == 1 && s.offset >= s.segments[0].size))
+}
+
+// countSpoolRecords 统计段文件中完整记录的数量
+func countSpoolRecords(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	n := 0
+	var size [4]byte
+	for {
+		if _, err := io.ReadFull(f, size[:]); err != nil {
+			return n
+		}
+		skip := int64(binary.BigEndian.Uint32(size[:]))
+		end, err := f.Seek(skip, io.SeekCurrent)
+		if err != nil {
+			return n
+		}
+		if fi, err := f.Stat(); err != nil || end > fi.Size() {
+			return n
+		}
+		n++
+	}
+}
+
+// append 在末尾追加一条记录，返回因超过上限被丢弃的记录数
+func (s *diskSpool) append(rec []byte) (int, error) {
+	if s.writer == nil || s.segments[len(s.segments)-1].size >= s.segmentBytes {
+		if err := s.newSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	buf := make([]byte, 4+len(rec))
+	binary.BigEndian.PutUint32(buf, uint32(len(rec)))
+	copy(buf[4:], rec)
+	n, err := s.writer.Write(buf)
+	s.segments[len(s.segments)-1].size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	s.segments[len(s.segments)-1].records++
+
+	return s.enforceLimit(), nil
+}
+
+// newSegment 新建一个写入段
+func (s *diskSpool) newSegment() error {
+	if s.writer != nil {
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+
+	var seq uint64 = 1
+	if len(s.segments) > 0 {
+		seq = s.segments[len(s.segments)-1].seq + 1
+	}
+	f, err := os.OpenFile(s.path(seq), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("create spool segment failed: %w", err)
+	}
+	s.writer = f
+	s.segments = append(s.segments, spoolSegment{seq: seq})
+	return nil
+}
+
+// enforceLimit 超过上限时删除最旧的段，正在写入的段除外，返回丢弃的记录数
+func (s *diskSpool) enforceLimit() int {
+	if s.maxBytes <= 0 {
+		return 0
+	}
+	var total int64
+	for _, seg := range s.segments {
+		total += seg.size
+	}
+	total -= s.offset
+
+	dropped := 0
+	for total > s.maxBytes && len(s.segments) > 1 {
+		total -= s.segments[0].size - s.offset
+		dropped += s.segments[0].records - s.consumed
+		s.removeHead()
+	}
+	return dropped
+}
+
+// removeHead 删除最旧的段
+func (s *diskSpool) removeHead() {
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+	if len(s.segments) == 1 && s.writer != nil {
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+	_ = os.Remove(s.path(s.segments[0].seq))
+	s.segments = s.segments[1:]
+	s.offset = 0
+	s.consumed = 0
+	s.pending = nil
+}
+
+// peek 返回最旧的待发送记录，没有记录时返回 nil
+func (s *diskSpool) peek() ([]byte, error) {
+	for s.pending == nil {
+		if len(s.segments) == 0 {
+			return nil, nil
+		}
+		head := s.segments[0]
+		if s.offset >= head.size {
+			if len(s.segments) == 1 {
+				// 全部发送完毕，删除段文件，后续追加时新建
+				s.removeHead()
+				return nil, nil
+			}
+			s.removeHead()
+			continue
+		}
+
+		if s.reader == nil {
+			f, err := os.Open(s.path(head.seq))
+			if err != nil {
+				return nil, err
+			}
+			if _, err := f.Seek(s.offset, io.SeekStart); err != nil {
+				f.Close()
+				return nil, err
+			}
+			s.reader = f
+		}
+
+		var size [4]byte
+		if _, err := io.ReadFull(s.reader, size[:]); err != nil {
+			// 上次运行中断时留下的不完整记录，跳过该段剩余内容
+			s.offset = head.size
+			continue
+		}
+		rec := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(s.reader, rec); err != nil {
+			s.offset = head.size
+			continue
+		}
+		s.pending = rec
+	}
+	return s.pending, nil
+}
+
+// advance 确认 peek 返回的记录已发送
+func (s *diskSpool) advance() {
+	if s.pending == nil {
+		return
+	}
+	s.offset += int64(4 + len(s.pending))
+	s.consumed++
+	s.pending = nil
+}
+
+// Close 实现 io.Closer 接口，未发送的记录保留在磁盘上
+func (s *diskSpool) Close() error {
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
@@ -0,0 +1,425 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// syslog 消息格式
+	SyslogRFC5424 = "5424" // 默认，字段作为结构化数据
+	SyslogRFC3164 = "3164" // 传统 BSD 格式，字段以 key=value 附加在消息后
+
+	// syslogSDID 结构化数据的 SD-ID，使用 RFC 5424 文档保留的企业号
+	syslogSDID = "fields@32473"
+)
+
+// SyslogSettings syslog 输出配置
+type SyslogSettings struct {
+	Network  string // "udp", "tcp", "unix", "unixgram"，为空时连接本机的 syslog 守护进程
+	Address  string // 地址，如 "127.0.0.1:514" 或 "/dev/log"；Network 为空时忽略
+	Facility string // 设施名，如 "user"、"daemon"、"local0"（默认 "user"）
+	Tag      string // APP-NAME / TAG（默认使用 LogNameBase）
+	RFC      string // 消息格式："5424"（默认）或 "3164"
+}
+
+// syslogFacilities 设施名到设施码的映射
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverity 将 logrus 级别映射为 syslog 严重级别
+func syslogSeverity(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 1 // alert
+	case logrus.FatalLevel:
+		return 2 // crit
+	case logrus.ErrorLevel:
+		return 3 // err
+	case logrus.WarnLevel:
+		return 4 // warning
+	case logrus.InfoLevel:
+		return 6 // info
+	default:
+		return 7 // debug
+	}
+}
+
+// validate 验证 syslog 配置
+func (s *SyslogSettings) validate() error {
+	switch s.Network {
+	case "", "udp", "tcp", "unix", "unixgram":
+	default:
+		return fmt.Errorf("unknown syslog network: %s", s.Network)
+	}
+	if s.Network != "" && s.Address == "" {
+		return fmt.Errorf("syslog address cannot be empty when network is set")
+	}
+	if _, ok := syslogFacilities[strings.ToLower(s.Facility)]; s.Facility != "" && !ok {
+		return fmt.Errorf("unknown syslog facility: %s", s.Facility)
+	}
+	switch s.RFC {
+	case "", SyslogRFC5424, SyslogRFC3164:
+	default:
+		return fmt.Errorf("unknown syslog rfc: %s", s.RFC)
+	}
+	return nil
+}
+
+// syslogSink 将条目发送到 syslog
+// 条目在 Send 中格式化后放入内存队列，由后台协程发送，队列满时丢弃条目，守护进程不可用时不会阻塞日志调用。
+// 写入失败时重新连接一次再重试，与标准库 log/syslog 的行为一致；重连失败后在退避时间内丢弃条目，
+// 避免每一条都等待连接超时
+type syslogSink struct {
+	facility int
+	tag      string
+	rfc      string
+	local    bool // 是否连接本机套接字，本机 RFC 3164 消息省略主机名
+	hostname string
+	pid      int
+
+	queue chan syslogRecord
+	done  chan struct{}
+
+	// 只由后台协程访问
+	network string
+	address string
+	conn    net.Conn
+	backoff backoff
+	retryAt time.Time // 断开后下次允许重连的时间
+
+	mu     sync.RWMutex // 保护 closed，Send 时持有读锁
+	closed bool
+
+	stats DeliveryStats // 原子访问
+}
+
+// syslogRecord 队列中的消息，flushed 不为 nil 时是 Flush 放入的标记
+type syslogRecord struct {
+	msg     []byte
+	flushed chan struct{}
+}
+
+func newSyslogSink(s *SyslogSettings, name string) (*syslogSink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	w := &syslogSink{
+		network:  s.Network,
+		address:  s.Address,
+		facility: syslogFacilities["user"],
+		tag:      s.Tag,
+		rfc:      s.RFC,
+		pid:      os.Getpid(),
+		queue:    make(chan syslogRecord, networkQueueSizeDef),
+		done:     make(chan struct{}),
+		backoff:  backoff{min: networkMinBackoffDef, max: networkMaxBackoffDef},
+	}
+	if s.Facility != "" {
+		w.facility = syslogFacilities[strings.ToLower(s.Facility)]
+	}
+	if w.tag == "" {
+		w.tag = name
+	}
+	if w.rfc == "" {
+		w.rfc = SyslogRFC5424
+	}
+	w.hostname, _ = os.Hostname()
+
+	// 创建时同步连接一次，配置错误或守护进程不可用时由 New 返回错误
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	w.local = w.network == "unix" || w.network == "unixgram"
+
+	go w.run()
+	return w, nil
+}
+
+// connect 建立连接，Network 为空时依次尝试本机常见的 syslog 套接字
+func (w *syslogSink) connect() error {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+
+	if w.network != "" {
+		conn, err := net.DialTimeout(w.network, w.address, networkDialTimeout)
+		if err != nil {
+			return err
+		}
+		w.conn = conn
+		return nil
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				w.network, w.address, w.conn = network, path, conn
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unix syslog delivery error")
+}
+
+// Send 实现 sink 接口，格式化条目后放入队列，队列满时丢弃
+func (w *syslogSink) Send(entry *logrus.Entry) error {
+	var b bytes.Buffer
+	if w.rfc == SyslogRFC3164 {
+		w.format3164(&b, entry)
+	} else {
+		w.format5424(&b, entry)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errors.New("syslog sink is closed")
+	}
+	select {
+	case w.queue <- syslogRecord{msg: b.Bytes()}:
+	default:
+		atomic.AddUint64(&w.stats.Dropped, 1)
+	}
+	return nil
+}
+
+func (w *syslogSink) sinkName() string {
+	return "syslog"
+}
+
+func (w *syslogSink) deliveryStats() DeliveryStats {
+	return DeliveryStats{
+		Sent:    atomic.LoadUint64(&w.stats.Sent),
+		Failed:  atomic.LoadUint64(&w.stats.Failed),
+		Dropped: atomic.LoadUint64(&w.stats.Dropped),
+	}
+}
+
+// Flush 等待调用前已放入队列的条目发送完成
+func (w *syslogSink) Flush(ctx context.Context) error {
+	rec := syslogRecord{flushed: make(chan struct{})}
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return nil
+	}
+	select {
+	case w.queue <- rec:
+		w.mu.RUnlock()
+	case <-ctx.Done():
+		w.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-rec.flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *syslogSink) run() {
+	defer close(w.done)
+
+	for rec := range w.queue {
+		if rec.flushed != nil {
+			close(rec.flushed)
+			continue
+		}
+		w.deliver(rec.msg)
+	}
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+// deliver 发送一条消息，写入失败时重新连接一次再重试，重连失败后在退避时间内直接丢弃
+func (w *syslogSink) deliver(msg []byte) {
+	if w.conn != nil && w.write(msg) {
+		atomic.AddUint64(&w.stats.Sent, 1)
+		return
+	}
+	if time.Now().Before(w.retryAt) {
+		atomic.AddUint64(&w.stats.Failed, 1)
+		return
+	}
+	if err := w.connect(); err != nil {
+		w.retryAt = time.Now().Add(w.backoff.duration())
+		atomic.AddUint64(&w.stats.Failed, 1)
+		fmt.Fprintf(os.Stderr, "Failed to connect to syslog: %v\n", err)
+		return
+	}
+	if !w.write(msg) {
+		w.retryAt = time.Now().Add(w.backoff.duration())
+		atomic.AddUint64(&w.stats.Failed, 1)
+		return
+	}
+	w.backoff.reset()
+	atomic.AddUint64(&w.stats.Sent, 1)
+}
+
+// write 通过当前连接发送一条消息，失败时断开连接
+func (w *syslogSink) write(msg []byte) bool {
+	_ = w.conn.SetWriteDeadline(time.Now().Add(networkWriteTimeout))
+	if _, err := w.conn.Write(w.frame(msg)); err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+		return false
+	}
+	return true
+}
+
+// frame 按传输方式为消息加上分帧：TCP 上 RFC 5424 使用长度前缀（RFC 6587），其余流式传输以换行结尾
+func (w *syslogSink) frame(msg []byte) []byte {
+	switch w.network {
+	case "tcp":
+		if w.rfc == SyslogRFC5424 {
+			return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		return append(msg, '\n')
+	case "unix":
+		return append(msg, '\n')
+	default:
+		return msg
+	}
+}
+
+// format5424 <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (w *syslogSink) format5424(b *bytes.Buffer, entry *logrus.Entry) {
+	fmt.Fprintf(b, "<%d>1 %s %s %s %d - ",
+		w.facility*8+syslogSeverity(entry.Level),
+		entry.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderField(w.hostname, 255),
+		syslogHeaderField(w.tag, 48),
+		w.pid)
+
+	if len(entry.Data) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		for _, k := range sortedKeys(entry.Data) {
+			b.WriteString(" ")
+			b.WriteString(syslogParamName(k))
+			b.WriteString(`="`)
+			b.WriteString(syslogParamValue(fieldString(entry.Data[k])))
+			b.WriteString(`"`)
+		}
+		b.WriteString("]")
+	}
+
+	b.WriteString(" ")
+	b.WriteString(entry.Message)
+}
+
+// format3164 <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG key=value
+func (w *syslogSink) format3164(b *bytes.Buffer, entry *logrus.Entry) {
+	fmt.Fprintf(b, "<%d>%s ", w.facility*8+syslogSeverity(entry.Level), entry.Time.Format(time.Stamp))
+	if !w.local {
+		b.WriteString(syslogHeaderField(w.hostname, 255))
+		b.WriteString(" ")
+	}
+	fmt.Fprintf(b, "%s[%d]: %s", w.tag, w.pid, entry.Message)
+	for _, k := range sortedKeys(entry.Data) {
+		fmt.Fprintf(b, " %s=%s", k, fieldString(entry.Data[k]))
+	}
+}
+
+// Close 实现 io.Closer 接口，等待队列中的条目发送完成
+func (w *syslogSink) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.done
+	return nil
+}
+
+// syslogHeaderField 头部字段只能包含可打印 ASCII 字符且不能为空
+func syslogHeaderField(s string, max int) string {
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < max; i++ {
+		if s[i] > 32 && s[i] < 127 {
+			b.WriteByte(s[i])
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	return b.String()
+}
+
+// syslogParamName PARAM-NAME 不能包含 '='、' '、']'、'"'，最长 32 个字符
+func syslogParamName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s) && b.Len() < 32; i++ {
+		c := s[i]
+		if c > 32 && c < 127 && c != '=' && c != ']' && c != '"' {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	return b.String()
+}
+
+// syslogParamValue PARAM-VALUE 中的 '"'、'\' 和 ']' 需要转义
+func syslogParamValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
+
+// sortedKeys 返回按字母排序的字段名
+func sortedKeys(data logrus.Fields) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldString 将字段值转换为字符串，error 使用其错误信息
+// 值为 nil 指针的 error 直接调用 Error 可能 panic，交给 fmt 处理，输出 <nil>
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return fmt.Sprint(v)
+		}
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
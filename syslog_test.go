@@ -0,0 +1,295 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// readPacket 从数据报套接字读取一条消息
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64*1024)
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read syslog message failed: %v", err)
+	}
+	return string(buf[:n])
+}
+
+// TestSyslogRFC5424UDP 测试通过 UDP 发送 RFC 5424 消息和结构化数据
+func TestSyslogRFC5424UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "svc"
+		s.Level = logrus.DebugLevel
+		s.Syslog = &SyslogSettings{
+			Network:  "udp",
+			Address:  conn.LocalAddr().String(),
+			Facility: "local0",
+		}
+	})
+
+	l.WithFields(logrus.Fields{
+		"user":  "alice",
+		"quote": `say "hi"]`,
+		"err":   errors.New("boom"),
+	}).Warn("disk almost full")
+
+	msg := readPacket(t, conn)
+	// local0(16)*8 + warning(4) = 132
+	re := regexp.MustCompile(`^<132>1 \S+ \S+ svc \d+ - \[fields@32473 (.+)\] disk almost full$`)
+	m := re.FindStringSubmatch(msg)
+	if m == nil {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+	if want := `err="boom" quote="say \"hi\"\]" user="alice"`; m[1] != want {
+		t.Errorf("structured data = %s, want %s", m[1], want)
+	}
+
+	l.Info("no fields")
+	re = regexp.MustCompile(`^<134>1 \S+ \S+ svc \d+ - - no fields$`)
+	if msg := readPacket(t, conn); !re.MatchString(msg) {
+		t.Errorf("unexpected message without fields: %q", msg)
+	}
+}
+
+// TestSyslogRFC3164Unixgram 测试通过本机数据报套接字发送 RFC 3164 消息
+func TestSyslogRFC3164Unixgram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "logger-ut-syslog-sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skipf("unixgram not supported: %v", err)
+	}
+	defer conn.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "svc"
+		s.Level = logrus.DebugLevel
+		s.Syslog = &SyslogSettings{
+			Network:  "unixgram",
+			Address:  path,
+			Facility: "daemon",
+			Tag:      "billing",
+			RFC:      SyslogRFC3164,
+		}
+	})
+	l.WithField("order", 42).Error("charge failed")
+
+	msg := readPacket(t, conn)
+	// daemon(3)*8 + err(3) = 27，本机套接字省略主机名
+	re := regexp.MustCompile(`^<27>\w{3} [ \d]\d \d{2}:\d{2}:\d{2} billing\[\d+\]: charge failed order=42$`)
+	if !re.MatchString(msg) {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
+
+// TestSyslogTCPOctetCounting 测试 TCP 上使用长度前缀分帧
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		r := bufio.NewReader(c)
+		length, err := r.ReadString(' ')
+		if err != nil {
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return
+		}
+		received <- string(buf)
+	}()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "svc"
+		s.Level = logrus.DebugLevel
+		s.Syslog = &SyslogSettings{Network: "tcp", Address: ln.Addr().String()}
+	})
+	l.Debug("over tcp")
+
+	select {
+	case msg := <-received:
+		// user(1)*8 + debug(7) = 15
+		if !strings.HasPrefix(msg, "<15>1 ") || !strings.HasSuffix(msg, " - - over tcp") {
+			t.Errorf("unexpected message: %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+// TestSyslogStalledDaemon 测试 syslog 守护进程不读取数据时日志调用不会阻塞，恢复连接后继续发送
+func TestSyslogStalledDaemon(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			accepted <- c
+		}
+	}()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "svc"
+		s.Level = logrus.DebugLevel
+		s.Syslog = &SyslogSettings{Network: "tcp", Address: ln.Addr().String()}
+	})
+	var stalled net.Conn
+	select {
+	case stalled = <-accepted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("syslog sink did not connect")
+	}
+
+	// 服务端不读取，写满套接字缓冲后发送会阻塞，队列随后写满，日志调用仍应立即返回并丢弃条目
+	payload := strings.Repeat("x", 4*1024)
+	var slowest time.Duration
+	for i := 0; i < 5*networkQueueSizeDef; i++ {
+		start := time.Now()
+		l.Info(payload)
+		if d := time.Since(start); d > slowest {
+			slowest = d
+		}
+	}
+	// 阻塞时每次调用需要等待写入超时
+	if slowest > networkWriteTimeout/2 {
+		t.Errorf("a logging call took %v while the syslog daemon was stalled", slowest)
+	}
+	if stats := l.DeliveryStats()["syslog"]; stats.Dropped == 0 {
+		t.Errorf("entries beyond the queue should be dropped, stats = %+v", stats)
+	}
+
+	// 断开后发送失败，后台协程在退避时间内丢弃条目，Flush 和 Close 不会长时间阻塞
+	stalled.Close()
+	ln.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := l.Flush(ctx); err != nil {
+		t.Errorf("Flush() = %v", err)
+	}
+}
+
+// TestSyslogSeverity 测试 logrus 级别到 syslog 严重级别的映射
+func TestSyslogSeverity(t *testing.T) {
+	want := map[logrus.Level]int{
+		logrus.PanicLevel: 1,
+		logrus.FatalLevel: 2,
+		logrus.ErrorLevel: 3,
+		logrus.WarnLevel:  4,
+		logrus.InfoLevel:  6,
+		logrus.DebugLevel: 7,
+		logrus.TraceLevel: 7,
+	}
+	for level, severity := range want {
+		if got := syslogSeverity(level); got != severity {
+			t.Errorf("syslogSeverity(%v) = %d, want %d", level, got, severity)
+		}
+	}
+}
+
+// TestSyslogSettingsYAML 测试 YAML 配置和参数校验
+func TestSyslogSettingsYAML(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+syslog:
+  network: udp
+  address: 127.0.0.1:514
+  facility: local3
+  tag: api
+  rfc: 3164
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SyslogSettings{Network: "udp", Address: "127.0.0.1:514", Facility: "local3", Tag: "api", RFC: SyslogRFC3164}
+	if s.Syslog == nil || *s.Syslog != want {
+		t.Fatalf("Syslog = %+v, want %+v", s.Syslog, want)
+	}
+
+	for _, bad := range []*SyslogSettings{
+		{Network: "sctp", Address: "x"},
+		{Network: "udp"},
+		{Facility: "nope"},
+		{RFC: "5425"},
+	} {
+		settings := NewSettings()
+		settings.Syslog = bad
+		if err := validateSettings(settings); err == nil {
+			t.Errorf("%+v should be rejected", bad)
+		}
+	}
+}
+
+// TestReloadReplacesSinks 测试重新加载时替换文件之外的输出
+func TestReloadReplacesSinks(t *testing.T) {
+	first, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	second, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.LogNameBase = "svc"
+		s.Level = logrus.DebugLevel
+		s.Syslog = &SyslogSettings{Network: "udp", Address: first.LocalAddr().String()}
+	})
+	l.Info("to first")
+	if msg := readPacket(t, first); !strings.HasSuffix(msg, "to first") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+
+	settings := *l.Settings()
+	settings.Syslog = &SyslogSettings{Network: "udp", Address: second.LocalAddr().String()}
+	if err := l.Reload(&settings); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("to second")
+	if msg := readPacket(t, second); !strings.HasSuffix(msg, "to second") {
+		t.Errorf("unexpected message: %q", msg)
+	}
+}
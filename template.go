@@ -0,0 +1,278 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// templateFormatDef 未设置模板时模板格式器使用的模板，输出与 withField 格式器相同
+const templateFormatDef = `{{.Timestamp}} - [{{.Level}}]: {{with .Caller}}{{.}} - {{end}}{{.Message}}{{with .RemainingFields}} {{.}}{{end}}`
+
+// 模板中 color 函数支持的颜色
+var templateColors = map[string]int{
+	"black":   30,
+	"red":     31,
+	"green":   32,
+	"yellow":  33,
+	"blue":    34,
+	"magenta": 35,
+	"cyan":    36,
+	"white":   37,
+	"gray":    90,
+}
+
+// TemplateFormatter 使用 text/template 模板格式化条目，模板的数据为 *TemplateEntry
+// 示例：{{.Timestamp}} {{pad 7 .Level | color "cyan"}} {{.Message}} user={{.FieldOr "user" "-"}} {{.RemainingFields}}
+// 模板中可以使用的函数：
+//   - pad N s / lpad N s：按宽度 N 左对齐 / 右对齐，也可以使用 printf "%-7s"
+//   - upper s / lower s：转换大小写
+//   - color name s：使用 ANSI 颜色输出，name 为 red、green、yellow、blue、magenta、cyan、white、gray、black，
+//     常量颜色名在解析时校验，运行时得到的未知颜色名原样输出文本
+//   - json v：输出为 JSON
+//
+// 输出不以换行结尾时自动追加换行
+type TemplateFormatter struct {
+	TimestampFormat string // .Timestamp 的格式
+	Colors          bool   // 是否输出颜色，关闭时 color 和 .Colorize 原样返回文本
+	NestedAsJSON    bool   // .RemainingFields 中 map、slice、struct 类型的值输出为 JSON
+
+	tmpl       *template.Template
+	referenced map[string]bool // 模板中通过 .Field、.FieldOr、.Fields.name 或 index .Fields "name" 引用的字段
+}
+
+// TemplateEntry 模板格式器中模板的数据
+type TemplateEntry struct {
+	Time      time.Time
+	Timestamp string        // 按 TimestampFormat 格式化的时间
+	Level     string        // 大写的级别，如 INFO、WARNING
+	Message   string        // 日志消息
+	Fields    logrus.Fields // 所有字段，不存在的字段建议使用 .Field 访问，避免输出 <no value>
+	Caller    string        // 调用者的 file:line，未开启调用者信息时为空
+	File      string        // 调用者文件
+	Line      int           // 调用者行号
+	Function  string        // 调用者函数
+
+	entry     *logrus.Entry
+	formatter *TemplateFormatter
+}
+
+// NewTemplateFormatter 解析模板并创建模板格式器，text 为空时使用默认模板
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	if text == "" {
+		text = templateFormatDef
+	}
+
+	f := &TemplateFormatter{referenced: make(map[string]bool)}
+	tmpl, err := template.New("log").Funcs(template.FuncMap{
+		"pad": func(width int, v interface{}) string {
+			return fmt.Sprintf("%-*s", width, fieldString(v))
+		},
+		"lpad": func(width int, v interface{}) string {
+			return fmt.Sprintf("%*s", width, fieldString(v))
+		},
+		"upper": func(v interface{}) string { return strings.ToUpper(fieldString(v)) },
+		"lower": func(v interface{}) string { return strings.ToLower(fieldString(v)) },
+		"color": func(name string, v interface{}) string {
+			// 颜色名为常量时已在解析时校验，运行时得到未知的颜色名时原样输出，避免丢弃条目
+			code, ok := templateColors[name]
+			if !ok {
+				return fieldString(v)
+			}
+			return f.colorize(code, fieldString(v))
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	f.tmpl = tmpl
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			collectTemplateFields(t.Tree.Root, f.referenced)
+			if err := checkTemplateColors(t.Tree.Root); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// Format 实现 logrus.Formatter 接口
+func (f *TemplateFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := &TemplateEntry{
+		Time:      entry.Time,
+		Timestamp: entry.Time.Format(f.TimestampFormat),
+		Level:     strings.ToUpper(entry.Level.String()),
+		Message:   entry.Message,
+		Fields:    entry.Data,
+		entry:     entry,
+		formatter: f,
+	}
+	if entry.HasCaller() {
+		data.File = entry.Caller.File
+		data.Line = entry.Caller.Line
+		data.Function = entry.Caller.Function
+		data.Caller = entry.Caller.File + ":" + strconv.Itoa(entry.Caller.Line)
+	}
+
+	b := entry.Buffer
+	if b == nil {
+		b = &bytes.Buffer{}
+	}
+	if err := f.tmpl.Execute(b, data); err != nil {
+		return nil, err
+	}
+	if b.Len() == 0 || b.Bytes()[b.Len()-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
+
+// colorize 在开启颜色时使用 ANSI 颜色包裹文本
+func (f *TemplateFormatter) colorize(code int, s string) string {
+	if !f.Colors {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", code, s)
+}
+
+// Field 返回字段的值，字段不存在时返回空字符串
+func (e *TemplateEntry) Field(name string) interface{} {
+	return e.FieldOr(name, "")
+}
+
+// FieldOr 返回字段的值，字段不存在时返回 def
+func (e *TemplateEntry) FieldOr(name string, def interface{}) interface{} {
+	if v, ok := e.Fields[name]; ok {
+		return v
+	}
+	return def
+}
+
+// RemainingFields 以 key=value 输出模板中未引用的字段，按字段名排序，需要时值加引号并转义
+func (e *TemplateEntry) RemainingFields() string {
+	var parts []string
+	for _, k := range sortedKeys(e.Fields) {
+		if e.formatter.referenced[k] {
+			continue
+		}
+		parts = append(parts, k+"="+fieldText(e.Fields[k], e.formatter.NestedAsJSON, true))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Colorize 按条目的级别为文本着色：Debug/Trace 灰色，Info 青色，Warn 黄色，Error 及以上红色
+func (e *TemplateEntry) Colorize(v interface{}) string {
+	code := templateColors["cyan"]
+	switch e.entry.Level {
+	case logrus.DebugLevel, logrus.TraceLevel:
+		code = templateColors["gray"]
+	case logrus.WarnLevel:
+		code = templateColors["yellow"]
+	case logrus.ErrorLevel, logrus.FatalLevel, logrus.PanicLevel:
+		code = templateColors["red"]
+	}
+	return e.formatter.colorize(code, fieldString(v))
+}
+
+// collectTemplateFields 收集模板中通过 .Field "name"、.FieldOr "name"、.Fields.name 或 index .Fields "name" 引用的字段名
+func collectTemplateFields(node parse.Node, out map[string]bool) {
+	walkTemplateCommands(node, func(n *parse.CommandNode) {
+		if len(n.Args) >= 2 {
+			if fn, ok := n.Args[0].(*parse.FieldNode); ok && len(fn.Ident) == 1 &&
+				(fn.Ident[0] == "Field" || fn.Ident[0] == "FieldOr") {
+				if s, ok := n.Args[1].(*parse.StringNode); ok {
+					out[s.Text] = true
+				}
+			}
+		}
+		// index .Fields "name"
+		if len(n.Args) >= 3 {
+			if id, ok := n.Args[0].(*parse.IdentifierNode); ok && id.Ident == "index" {
+				if fn, ok := n.Args[1].(*parse.FieldNode); ok && len(fn.Ident) == 1 && fn.Ident[0] == "Fields" {
+					if s, ok := n.Args[2].(*parse.StringNode); ok {
+						out[s.Text] = true
+					}
+				}
+			}
+		}
+		for _, a := range n.Args {
+			if fn, ok := a.(*parse.FieldNode); ok && len(fn.Ident) >= 2 && fn.Ident[0] == "Fields" {
+				out[fn.Ident[1]] = true
+			}
+		}
+	})
+}
+
+// checkTemplateColors 检查模板中 color 函数使用的常量颜色名
+func checkTemplateColors(node parse.Node) error {
+	var err error
+	walkTemplateCommands(node, func(n *parse.CommandNode) {
+		if err != nil || len(n.Args) < 2 {
+			return
+		}
+		if id, ok := n.Args[0].(*parse.IdentifierNode); ok && id.Ident == "color" {
+			if s, ok := n.Args[1].(*parse.StringNode); ok {
+				if _, ok := templateColors[s.Text]; !ok {
+					err = fmt.Errorf("unknown color %q", s.Text)
+				}
+			}
+		}
+	})
+	return err
+}
+
+// walkTemplateCommands 遍历模板中的所有命令，包括条件、循环和括号内的子管道
+func walkTemplateCommands(node parse.Node, fn func(*parse.CommandNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplateCommands(c, fn)
+		}
+	case *parse.ActionNode:
+		walkTemplateCommands(n.Pipe, fn)
+	case *parse.IfNode:
+		walkBranchCommands(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		walkBranchCommands(&n.BranchNode, fn)
+	case *parse.WithNode:
+		walkBranchCommands(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		walkTemplateCommands(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplateCommands(c, fn)
+		}
+	case *parse.CommandNode:
+		fn(n)
+		for _, a := range n.Args {
+			walkTemplateCommands(a, fn)
+		}
+	case *parse.ChainNode:
+		walkTemplateCommands(n.Node, fn)
+	}
+}
+
+func walkBranchCommands(n *parse.BranchNode, fn func(*parse.CommandNode)) {
+	walkTemplateCommands(n.Pipe, fn)
+	walkTemplateCommands(n.List, fn)
+	walkTemplateCommands(n.ElseList, fn)
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestTemplateFormatter 测试模板中的字段、默认值、条件、填充和剩余字段
+func TestTemplateFormatter(t *testing.T) {
+	f, err := NewTemplateFormatter(`{{pad 7 .Level}}|{{.Message}}|user={{.FieldOr "user" "-"}}` +
+		`{{if .Fields.admin}} [admin]{{end}}|{{upper (.Field "region")}}|{{.RemainingFields}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := logrus.Fields{"user": "alice", "admin": true, "region": "eu", "b": "x y", "a": 1}
+	if got, want := formatWithFields(t, f, data), "INFO   |hello|user=alice [admin]|EU|a=1 b=\"x y\"\n"; got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+	if got, want := formatWithFields(t, f, logrus.Fields{"a": 1}), "INFO   |hello|user=-||a=1\n"; got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	// 通过 index 引用的字段不再出现在剩余字段中
+	f, err = NewTemplateFormatter(`{{.Message}} id={{index .Fields "req.id"}} {{.RemainingFields}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := formatWithFields(t, f, logrus.Fields{"req.id": 7, "a": 1}), "hello id=7 a=1\n"; got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+
+	// 调用者、json、右对齐
+	f, err = NewTemplateFormatter("{{.Caller}} {{.Function}} {{lpad 4 .Line}} {{json .Fields}}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := logrus.NewEntry(logrus.New())
+	entry.Data = logrus.Fields{"k": []int{1, 2}}
+	entry.Caller = &runtime.Frame{File: "/src/main.go", Line: 42, Function: "main.run"}
+	entry.Logger.ReportCaller = true
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/src/main.go:42 main.run   42 {\"k\":[1,2]}\n"; string(b) != want {
+		t.Errorf("got %q, want %q", b, want)
+	}
+}
+
+// TestTemplateFormatterColors 测试颜色只在开启时输出
+func TestTemplateFormatterColors(t *testing.T) {
+	f, err := NewTemplateFormatter(`{{.Colorize .Level}} {{color "green" .Message}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatWithFields(t, f, nil); got != "INFO hello\n" {
+		t.Errorf("colors disabled: got %q", got)
+	}
+	f.Colors = true
+	if got, want := formatWithFields(t, f, nil), "\x1b[36mINFO\x1b[0m \x1b[32mhello\x1b[0m\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// 常量颜色名在解析时校验，包括条件和括号内的子管道
+	for _, text := range []string{
+		`{{color "pink" .Message}}`,
+		`{{if .Caller}}{{.Message | color "pink"}}{{end}}`,
+		`{{pad 7 (color "pink" .Level)}}`,
+	} {
+		if _, err := NewTemplateFormatter(text); err == nil || !strings.Contains(err.Error(), `unknown color "pink"`) {
+			t.Errorf("%s: expected unknown color error, got %v", text, err)
+		}
+	}
+
+	// 运行时得到的未知颜色名原样输出，不丢弃条目
+	f, err = NewTemplateFormatter(`{{color (.Field "c") .Message}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Colors = true
+	if got := formatWithFields(t, f, logrus.Fields{"c": "pink"}); got != "hello\n" {
+		t.Errorf("unknown runtime color: got %q", got)
+	}
+}
+
+// TestTemplateFormatterSettings 测试通过 formatter_type: template 和 template_format 配置模板
+func TestTemplateFormatterSettings(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+formatter_type: template
+timestamp_format: "15:04:05"
+template_format: '{{.Timestamp}} {{.Level}} {{.Message}} {{.RemainingFields}}'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	f, ok := (&FormatterFactory{}).CreateFormatter(s).(*TemplateFormatter)
+	if !ok {
+		t.Fatal("expected TemplateFormatter")
+	}
+	if got := formatWithFields(t, f, logrus.Fields{"k": "v"}); got != "03:04:05 INFO hello k=v\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+
+	// 默认模板与 withField 格式器的输出一致
+	s = NewSettings()
+	s.FormatterType = FormatterTypeTemplate
+	data := logrus.Fields{"user": "li lei", "id": 1}
+	want := formatWithFields(t, (&FormatterFactory{}).CreateFormatter(NewSettings()), data)
+	if got := formatWithFields(t, (&FormatterFactory{}).CreateFormatter(s), data); got != want {
+		t.Errorf("default template: got %q, want %q", got, want)
+	}
+
+	s.TemplateFormat = "{{.Message"
+	if err := validateSettings(s); err == nil || !strings.Contains(err.Error(), "TemplateFormat") {
+		t.Errorf("invalid template should be rejected, got %v", err)
+	}
+	if _, err := (&FormatterFactory{}).CreateFormatterWithError(s); err == nil {
+		t.Error("CreateFormatterWithError should return the template parse error")
+	}
+	s.TemplateFormat = `{{color "pink" .Message}}`
+	s.LogRootFPath = t.TempDir()
+	if _, err := New(s); err == nil || !strings.Contains(err.Error(), "unknown color") {
+		t.Errorf("New should reject unknown colors, got %v", err)
+	}
+}
+
+// TestTemplateFormatterLogFormat 测试未设置 template_format 时 template 格式器使用 log_format
+func TestTemplateFormatterLogFormat(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+formatter_type: template
+log_format: '[{{.Level}}] {{.Message}} {{.RemainingFields}}'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	if got := formatWithFields(t, (&FormatterFactory{}).CreateFormatter(s), logrus.Fields{"k": "v"}); got != "[INFO] hello k=v\n" {
+		t.Errorf("file output = %q, want log_format template", got)
+	}
+	if got := formatWithFields(t, consoleFormatter(s), nil); got != "[INFO] hello \n" {
+		t.Errorf("console output = %q, want log_format template", got)
+	}
+
+	s.LogFormat = "{{.Message"
+	if err := validateSettings(s); err == nil || !strings.Contains(err.Error(), "LogFormat") {
+		t.Errorf("invalid log_format template should be rejected, got %v", err)
+	}
+}
+
+// TestTemplateFormatterWithEasy 测试文件使用 easy 格式器、控制台使用模板格式器时两者的格式互不影响
+func TestTemplateFormatterWithEasy(t *testing.T) {
+	s, err := parseSettingsYAML([]byte(`
+formatter_type: easy
+log_format: "%lvl%: %msg%\n"
+console_formatter_type: template
+template_format: '[{{.Level}}] {{.Message}}'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSettings(s); err != nil {
+		t.Fatal(err)
+	}
+
+	file := formatWithFields(t, (&FormatterFactory{}).CreateFormatter(s), nil)
+	if file != "INFO: hello\n" {
+		t.Errorf("file output = %q, want easy format", file)
+	}
+	console := formatWithFields(t, consoleFormatter(s), nil)
+	if console != "[INFO] hello\n" {
+		t.Errorf("console output = %q, want template format", console)
+	}
+}
+
+// TestTemplateFormatterLogger 测试日志器使用模板格式器输出
+func TestTemplateFormatterLogger(t *testing.T) {
+	settings := NewSettings()
+	settings.FormatterType = FormatterTypeTemplate
+	settings.TemplateFormat = `[{{.Level}}] {{.Message}}{{with .RemainingFields}} ({{.}}){{end}}`
+	l, buf := newModuleTestLogger(t, settings)
+
+	l.WithField("order", 7).Warn("shipped")
+	l.Info("plain")
+	if got, want := buf.String(), "[WARNING] shipped (order=7)\n[INFO] plain\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
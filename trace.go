@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// 条目中携带追踪上下文的字段名
+	TraceIDFieldKey = "trace_id"
+	SpanIDFieldKey  = "span_id"
+)
+
+// TraceExtractor 从 context 中提取 trace ID 和 span ID（十六进制），可用于对接 OpenTelemetry 等追踪库
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+type traceContextKey struct{}
+
+type traceContext struct {
+	traceID string
+	spanID  string
+}
+
+// ContextWithTrace 返回携带 trace ID 和 span ID 的 context
+// 通过 WithContext 记录的条目会从中取得追踪上下文
+func ContextWithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, traceContext{traceID: traceID, spanID: spanID})
+}
+
+// TraceFromContext 返回 ContextWithTrace 设置的 trace ID 和 span ID
+func TraceFromContext(ctx context.Context) (traceID, spanID string) {
+	if ctx == nil {
+		return "", ""
+	}
+	tc, _ := ctx.Value(traceContextKey{}).(traceContext)
+	return tc.traceID, tc.spanID
+}
+
+// entryTrace 返回条目的追踪上下文
+// 依次从 trace_id/span_id 字段、ContextWithTrace 和 extract 中查找，先找到的优先
+func entryTrace(entry *logrus.Entry, extract TraceExtractor) (traceID, spanID string) {
+	if v, ok := entry.Data[TraceIDFieldKey]; ok {
+		traceID = fieldString(v)
+	}
+	if v, ok := entry.Data[SpanIDFieldKey]; ok {
+		spanID = fieldString(v)
+	}
+	if (traceID == "" || spanID == "") && entry.Context != nil {
+		t, s := TraceFromContext(entry.Context)
+		if traceID == "" {
+			traceID = t
+		}
+		if spanID == "" {
+			spanID = s
+		}
+		if (traceID == "" || spanID == "") && extract != nil {
+			t, s = extract(entry.Context)
+			if traceID == "" {
+				traceID = t
+			}
+			if spanID == "" {
+				spanID = s
+			}
+		}
+	}
+	return traceID, spanID
+}
+
+// validTraceID 判断是否为指定字节数的非全零十六进制 ID
+func validTraceID(id string, size int) bool {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != size {
+		return false
+	}
+	for _, c := range b {
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
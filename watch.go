@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// WatchOptions YAML 配置热加载选项
+type WatchOptions struct {
+	Interval time.Duration   // 轮询间隔（默认 2 秒）
+	OnReload func(*Settings) // 配置成功加载后的回调
+}
+
+// YAMLWatcher 监视 YAML 配置文件，文件内容变化时重新加载日志配置
+type YAMLWatcher struct {
+	path     string
+	interval time.Duration
+	onReload func(*Settings)
+
+	errs     chan error
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	lastContent []byte
+	pending     []byte // 上一次轮询读到的、与 lastContent 不同的内容，等待下一次轮询确认
+}
+
+const (
+	watchIntervalDef = 2 * time.Second
+	watchErrorsCap   = 16
+)
+
+// WatchYAML 加载 YAML 配置并开始监视文件变化
+// 连续两次轮询读到相同的新内容后才调用 LoadSettingsFromYAML 并通过 ReloadLoggerSettings 原地更新日志器；
+// 配置无效时保留之前的有效配置，并将错误发送到 Errors 通道。首次加载失败时直接返回错误
+func WatchYAML(path string, inOptions ...*WatchOptions) (*YAMLWatcher, error) {
+	var options *WatchOptions
+	if len(inOptions) > 0 && inOptions[0] != nil {
+		options = inOptions[0]
+	} else {
+		options = &WatchOptions{}
+	}
+
+	w := &YAMLWatcher{
+		path:     path,
+		interval: options.Interval,
+		onReload: options.OnReload,
+		errs:     make(chan error, watchErrorsCap),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if w.interval <= 0 {
+		w.interval = watchIntervalDef
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.apply(content); err != nil {
+		return nil, err
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Errors 返回配置加载错误通道
+// 通道带缓冲，缓冲区满时新的错误会被丢弃，不会阻塞监视协程；Stop 后通道被关闭
+func (w *YAMLWatcher) Errors() <-chan error {
+	return w.errs
+}
+
+// Stop 停止监视，重复调用是安全的
+func (w *YAMLWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+func (w *YAMLWatcher) run() {
+	defer close(w.done)
+	defer close(w.errs)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 读取配置文件，内容变化且连续两次轮询读到相同内容时重新加载
+// 编辑器或部署工具可能分多次写入文件，写了一半的内容仍可能是合法的 YAML，
+// 直接应用会把未写入的配置项重置为默认值，因此等待内容稳定后再应用
+func (w *YAMLWatcher) check() {
+	content, err := os.ReadFile(w.path)
+	if err != nil {
+		// 文件可能正在被编辑器替换，报告错误后等待下一次轮询
+		w.reportError(fmt.Errorf("read config %s failed: %w", w.path, err))
+		return
+	}
+	if bytes.Equal(content, w.lastContent) {
+		w.pending = nil
+		return
+	}
+	if w.pending == nil || !bytes.Equal(content, w.pending) {
+		w.pending = content
+		return
+	}
+
+	w.pending = nil
+	if err := w.apply(content); err != nil {
+		w.reportError(err)
+	}
+}
+
+// apply 解析并应用配置内容
+// 无论成功与否都记录内容，避免同一份无效配置被重复报告
+func (w *YAMLWatcher) apply(content []byte) error {
+	w.lastContent = content
+
+	settings, err := parseSettingsYAML(content)
+	if err != nil {
+		return fmt.Errorf("parse config %s failed: %w", w.path, err)
+	}
+	if err := ReloadLoggerSettings(settings); err != nil {
+		return fmt.Errorf("reload config %s failed: %w", w.path, err)
+	}
+
+	if w.onReload != nil {
+		w.onReload(settings)
+	}
+	return nil
+}
+
+func (w *YAMLWatcher) reportError(err error) {
+	select {
+	case w.errs <- err:
+	default:
+	}
+}
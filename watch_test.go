@@ -0,0 +1,357 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestWatchYAMLReload 测试配置文件变化后原地更新日志器
+func TestWatchYAMLReload(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "config.yaml")
+	// 先写临时文件再重命名，避免监视协程读到写了一半的配置
+	writeConfig := func(content string) {
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("log_root: " + root + "\nlog_name_base: watch\nlevel: info\nmax_size_mb: 1\n")
+
+	reloaded := make(chan *Settings, 4)
+	w, err := WatchYAML(path, &WatchOptions{
+		Interval: 10 * time.Millisecond,
+		OnReload: func(s *Settings) { reloaded <- s },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	defer Close()
+	<-reloaded
+
+	before := Default()
+	if before.GetLevel() != logrus.InfoLevel {
+		t.Fatalf("initial level = %v, want info", before.GetLevel())
+	}
+
+	writeConfig("log_root: " + root + "\nlog_name_base: watch\nlevel: debug\nmax_size_mb: 1\n")
+	select {
+	case s := <-reloaded:
+		if s.Level != logrus.DebugLevel {
+			t.Errorf("reloaded level = %v, want debug", s.Level)
+		}
+	case err := <-w.Errors():
+		t.Fatalf("unexpected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	if Default() != before {
+		t.Error("reload should update the default logger in place")
+	}
+	if Default().GetLevel() != logrus.DebugLevel {
+		t.Errorf("level after reload = %v, want debug", Default().GetLevel())
+	}
+
+	// 无效配置应报告错误并保留之前的配置
+	writeConfig("log_root: " + root + "\nlog_name_base: \"bad/name\"\nlevel: info\n")
+	select {
+	case err := <-w.Errors():
+		if !strings.Contains(err.Error(), "LogNameBase") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-reloaded:
+		t.Fatal("invalid config should not be applied")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload error")
+	}
+
+	if Default().GetLevel() != logrus.DebugLevel {
+		t.Errorf("level after invalid config = %v, want debug", Default().GetLevel())
+	}
+
+	// 拼写错误的级别应报告错误，而不是悄悄改为 info
+	writeConfig("log_root: " + root + "\nlog_name_base: watch\nlevel: degub\nmax_size_mb: 1\n")
+	select {
+	case err := <-w.Errors():
+		if !strings.Contains(err.Error(), `unknown level "degub"`) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-reloaded:
+		t.Fatal("config with an invalid level should not be applied")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload error")
+	}
+	if Default().GetLevel() != logrus.DebugLevel {
+		t.Errorf("level after invalid level = %v, want debug", Default().GetLevel())
+	}
+}
+
+// TestWatchYAMLPartialWrite 测试写了一半的配置在内容稳定前不会被应用
+func TestWatchYAMLPartialWrite(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-watch-partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "config.yaml")
+	full := "log_root: " + root + "\nlog_name_base: partial\nlevel: debug\nmax_size_mb: 1\n"
+	if err := os.WriteFile(path, []byte(full), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var reloads []*Settings
+	// 使用很长的轮询间隔，由测试直接调用 check 模拟轮询
+	w, err := WatchYAML(path, &WatchOptions{
+		Interval: time.Hour,
+		OnReload: func(s *Settings) { reloads = append(reloads, s) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	defer Close()
+
+	// 截断的内容仍是合法的 YAML，但缺少 level 等配置
+	truncated := full[:strings.Index(full, "level:")]
+	if err := os.WriteFile(path, []byte(truncated), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	if len(reloads) != 1 || Default().GetLevel() != logrus.DebugLevel {
+		t.Fatalf("truncated config should not be applied after one poll, level = %v", Default().GetLevel())
+	}
+
+	// 写入完成后内容恢复，截断的内容从未被应用
+	updated := strings.Replace(full, "level: debug", "level: warn", 1)
+	if err := os.WriteFile(path, []byte(updated), 0600); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	if len(reloads) != 1 {
+		t.Fatal("changed content should wait for the next poll")
+	}
+	w.check()
+	if len(reloads) != 2 || reloads[1].Level != logrus.WarnLevel {
+		t.Fatalf("stable content should be applied, reloads = %d", len(reloads))
+	}
+	if Default().GetLevel() != logrus.WarnLevel {
+		t.Errorf("level after reload = %v, want warn", Default().GetLevel())
+	}
+}
+
+// TestLoggerReloadKeepsInFlightLines 测试并发写入期间切换配置不会丢失日志
+func TestLoggerReloadKeepsInFlightLines(t *testing.T) {
+	root, err := os.MkdirTemp("", "logger-ut-reload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	newSettings := func(name string) *Settings {
+		settings := NewSettings()
+		settings.LogRootFPath = root
+		settings.LogNameBase = name
+		settings.MaxSizeMB = 10
+		return settings
+	}
+
+	l, err := New(newSettings("reload_a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const numWriters = 8
+	const numMessages = 200
+	var wg sync.WaitGroup
+	for i := 0; i < numWriters; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < numMessages; j++ {
+				l.Infof("writer %d message %d", id, j)
+			}
+		}(i)
+	}
+
+	names := []string{"reload_b", "reload_a", "reload_b"}
+	for _, name := range names {
+		time.Sleep(time.Millisecond)
+		if err := l.Reload(newSettings(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	total := 0
+	for _, name := range []string{"reload_a", "reload_b"} {
+		f, err := os.Open(filepath.Join(root, name+".log"))
+		if os.IsNotExist(err) {
+			// 写入可能在重新加载前全部完成，此时新文件不会被创建
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			total++
+		}
+		f.Close()
+	}
+
+	if total != numWriters*numMessages {
+		t.Errorf("expected %d lines, got %d", numWriters*numMessages, total)
+	}
+}
+
+// TestLoggerReloadReopensSpool 测试重新加载时旧的磁盘缓冲先关闭再由新输出接管，条目不重复也不丢失
+func TestLoggerReloadReopensSpool(t *testing.T) {
+	// 先占用再释放一个端口，此时连接会被拒绝
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.Network = &NetworkSettings{
+			Address:       addr,
+			FormatterType: FormatterTypeJSON,
+			MinBackoff:    10 * time.Millisecond,
+			MaxBackoff:    50 * time.Millisecond,
+		}
+	})
+	// 不等待条目写入缓冲就重新加载，旧输出关闭时才把队列中的条目写入缓冲
+	const numOffline = 200
+	for i := 0; i < numOffline; i++ {
+		l.Infof("offline %d", i)
+	}
+
+	settings := *l.Settings()
+	if err := l.Reload(&settings); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after reload")
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	srv := newLineServer(t, ln)
+
+	for i := 0; i < numOffline; i++ {
+		if msg, want := srv.next(t), fmt.Sprintf("offline %d", i); msg != want {
+			t.Fatalf("msg = %q, want %q", msg, want)
+		}
+	}
+	if msg := srv.next(t); msg != "after reload" {
+		t.Fatalf("msg = %q, want after reload", msg)
+	}
+	select {
+	case line := <-srv.lines:
+		t.Errorf("unexpected duplicate line %q", line)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestReloadLoggerSettingsAllOrNothing 测试任一日志器无法创建时所有日志器保持原有配置
+func TestReloadLoggerSettingsAllOrNothing(t *testing.T) {
+	backup := backupState()
+	defer backup.restoreState()
+
+	root, err := os.MkdirTemp("", "logger-ut-reload-all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	settings := NewSettings()
+	settings.LogRootFPath = root
+	settings.LogNameBase = "app"
+	if err := SetLoggerSettingsWithError(settings); err != nil {
+		t.Fatal(err)
+	}
+	defer Close()
+	worker := Named("worker")
+
+	// 默认日志器的新设置有效，但 worker 的覆盖项无效
+	updated := NewSettings()
+	updated.LogRootFPath = root
+	updated.LogNameBase = "app_v2"
+	updated.Level = logrus.DebugLevel
+	updated.Loggers = map[string]*LoggerOverride{
+		"worker": {LogNameBase: "bad/name"},
+	}
+	if err := ReloadLoggerSettings(updated); err == nil {
+		t.Fatal("expected error for invalid named logger settings")
+	}
+
+	if level := Default().GetLevel(); level != logrus.InfoLevel {
+		t.Errorf("default level = %v, want unchanged info", level)
+	}
+	if name := Default().Settings().LogNameBase; name != "app" {
+		t.Errorf("default LogNameBase = %q, want unchanged app", name)
+	}
+	if name := worker.Settings().LogNameBase; name != "worker" {
+		t.Errorf("worker LogNameBase = %q, want unchanged worker", name)
+	}
+}
+
+// TestLoggerReloadRestoreFailure 测试新输出和原有输出都无法创建时 Reload 报告恢复失败
+func TestLoggerReloadRestoreFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 连接在监听队列中即可建立，不需要接受
+	addr := ln.Addr().String()
+
+	l := newSinkTestLogger(t, func(s *Settings) {
+		s.Syslog = &SyslogSettings{Network: "tcp", Address: addr}
+	})
+
+	// 守护进程停止后，新设置和原有设置中的 syslog 输出都无法连接
+	ln.Close()
+	updated := *l.Settings()
+	updated.Level = logrus.DebugLevel
+	err = l.Reload(&updated)
+	if err == nil || !strings.Contains(err.Error(), "restore previous sinks failed") {
+		t.Fatalf("Reload() = %v, want restore error", err)
+	}
+	if n := len(l.sinkHook.sinks); n != 0 {
+		t.Errorf("sinks after failed restore = %d, want 0", n)
+	}
+	if l.GetLevel() != logrus.InfoLevel {
+		t.Errorf("level = %v, want unchanged info", l.GetLevel())
+	}
+}